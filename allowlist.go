@@ -0,0 +1,131 @@
+package tldbuster
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// AllowlistPolicy decides what happens to results matching the allowlist.
+type AllowlistPolicy int
+
+const (
+	// AllowlistSuppress drops matching results and counts them.
+	AllowlistSuppress AllowlistPolicy = iota
+	// AllowlistTag keeps matching results and marks them as owned.
+	AllowlistTag
+)
+
+// Allowlist describes domains known to belong to the brand: exact domain
+// names, name server patterns, registrars and registrant organisations.
+type Allowlist struct {
+	Domains        []string
+	NameServers    []string
+	Registrars     []string
+	RegistrantOrgs []string
+}
+
+// LoadAllowlistFile reads an allowlist file.
+func LoadAllowlistFile(name string) (*Allowlist, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadAllowlist(file)
+}
+
+// LoadAllowlist parses "kind value" lines, where kind is domain, ns,
+// registrar or org. Name server values may use shell wildcards, e.g.
+// "ns *.example-dns.net". Empty lines and # comments are skipped.
+func LoadAllowlist(r io.Reader) (*Allowlist, error) {
+	l := &Allowlist{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, value, ok := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("allowlist line %d: expected \"kind value\"", n)
+		}
+		switch strings.ToLower(kind) {
+		case "domain":
+			l.Domains = append(l.Domains, strings.ToLower(strings.TrimSuffix(value, ".")))
+		case "ns":
+			pattern := strings.ToLower(strings.TrimSuffix(value, "."))
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("allowlist line %d: %w", n, err)
+			}
+			l.NameServers = append(l.NameServers, pattern)
+		case "registrar":
+			l.Registrars = append(l.Registrars, value)
+		case "org":
+			l.RegistrantOrgs = append(l.RegistrantOrgs, value)
+		default:
+			return nil, fmt.Errorf("allowlist line %d: unknown kind %q", n, kind)
+		}
+	}
+	return l, scanner.Err()
+}
+
+// matchDomain reports whether domain is listed exactly.
+func (l *Allowlist) matchDomain(domain string) bool {
+	if l == nil {
+		return false
+	}
+	for _, d := range l.Domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether a result matches the allowlist and returns the
+// rule that matched. Name servers are taken from the delegation, the NS
+// records and the WHOIS record, whichever were collected.
+func (l *Allowlist) Match(res Result) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	if l.matchDomain(res.Domain) {
+		return "domain " + res.Domain, true
+	}
+	ns := append([]string(nil), res.DelegationNS...)
+	if res.Records != nil {
+		ns = append(ns, res.Records.NS...)
+	}
+	if res.Whois != nil {
+		for _, host := range res.Whois.NameServers {
+			ns = append(ns, strings.ToLower(strings.TrimSuffix(host, ".")))
+		}
+	}
+	for _, pattern := range l.NameServers {
+		for _, host := range ns {
+			if ok, _ := path.Match(pattern, host); ok {
+				return "ns " + host, true
+			}
+		}
+	}
+	if rec := res.Whois; rec != nil {
+		for _, r := range l.Registrars {
+			if rec.Registrar != "" && normalizeOrg(r) == normalizeOrg(rec.Registrar) {
+				return "registrar " + rec.Registrar, true
+			}
+		}
+		for _, org := range l.RegistrantOrgs {
+			for _, v := range []string{rec.RegistrantOrg, rec.Registrant} {
+				if v != "" && normalizeOrg(org) == normalizeOrg(v) {
+					return "org " + v, true
+				}
+			}
+		}
+	}
+	return "", false
+}
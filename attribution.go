@@ -0,0 +1,233 @@
+package tldbuster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Ownership is the attribution of a variant relative to the original domain.
+type Ownership string
+
+const (
+	// OwnershipLikelyOwned means the variant shares strong signals with the
+	// original, typically a defensive registration.
+	OwnershipLikelyOwned Ownership = "likely-owned"
+	// OwnershipLikelyThirdParty means the variant shares nothing with the
+	// original although enough data was available to compare.
+	OwnershipLikelyThirdParty Ownership = "likely-third-party"
+	// OwnershipUnknown means the signals are too weak or too scarce.
+	OwnershipUnknown Ownership = "unknown"
+	// OwnershipOwned means the variant matches the allowlist.
+	OwnershipOwned Ownership = "owned"
+)
+
+// ownedScore is the signal weight from which a variant is likely owned.
+const ownedScore = 3
+
+// Origin ASNs are looked up through the Team Cymru DNS service.
+const (
+	cymruOrigin4 = "origin.asn.cymru.com"
+	cymruOrigin6 = "origin6.asn.cymru.com"
+)
+
+// Fingerprint summarises the infrastructure and registration of a domain.
+type Fingerprint struct {
+	Domain        string   `json:"domain"`
+	NameServers   []string `json:"name_servers,omitempty"`
+	MX            []string `json:"mx,omitempty"`
+	Registrar     string   `json:"registrar,omitempty"`
+	RegistrantOrg string   `json:"registrant_org,omitempty"`
+	IPs           []string `json:"ips,omitempty"`
+	ASNs          []string `json:"asns,omitempty"`
+	// CNAME is the canonical name the domain points to, if any.
+	CNAME string `json:"cname,omitempty"`
+}
+
+// empty reports whether the fingerprint holds anything to compare.
+func (f *Fingerprint) empty() bool {
+	return len(f.NameServers) == 0 && len(f.MX) == 0 && f.Registrar == "" &&
+		f.RegistrantOrg == "" && len(f.IPs) == 0
+}
+
+// Fingerprint captures the baseline of domain: its name servers, MX hosts,
+// registrar, registrant organisation, addresses and their origin ASNs.
+func (s *Scanner) Fingerprint(ctx context.Context, domain string) *Fingerprint {
+	_, ips := s.checkDomain(ctx, domain)
+	recs := s.collectRecords(ctx, domain)
+	var rec *WhoisRecord
+	if s.whois != nil {
+		var err error
+		if rec, err = s.whois.Lookup(ctx, domain); err != nil {
+			s.debugf("WHOIS lookup for %s failed: %v", domain, err)
+		}
+	}
+	return s.fingerprint(ctx, domain, ips, recs, rec)
+}
+
+// fingerprint assembles a fingerprint from data already collected.
+func (s *Scanner) fingerprint(ctx context.Context, domain string, ips []string, recs *DNSRecords, rec *WhoisRecord) *Fingerprint {
+	f := &Fingerprint{Domain: domain, IPs: ips}
+	if recs != nil {
+		f.NameServers = recs.NS
+		for _, mx := range recs.MX {
+			f.MX = append(f.MX, mx.Host)
+		}
+		f.CNAME = recs.CNAME
+	}
+	if rec != nil {
+		f.Registrar = rec.Registrar
+		if privacy, _ := rec.Privacy(); privacy == PrivacyPresent {
+			f.RegistrantOrg = rec.RegistrantOrg
+			if f.RegistrantOrg == "" {
+				f.RegistrantOrg = rec.Registrant
+			}
+		}
+	}
+	asns := make(map[string]struct{})
+	for _, ip := range ips {
+		for _, asn := range s.originASNs(ctx, ip) {
+			asns[asn] = struct{}{}
+		}
+	}
+	f.ASNs = sortedKeys(asns)
+	return f
+}
+
+// originASNs returns the origin AS numbers announcing ip, cached per address.
+func (s *Scanner) originASNs(ctx context.Context, ip string) []string {
+	return s.asns.get(ip, func() []string {
+		ctx := withEvidence(ctx, nil)
+		name := cymruName(ip)
+		if name == "" {
+			return nil
+		}
+		resp, status := s.query(ctx, name, dnsmessage.TypeTXT)
+		if status != StatusResolved {
+			return nil
+		}
+		var asns []string
+		for _, rr := range resp.Answers {
+			txt, ok := rr.Body.(*dnsmessage.TXTResource)
+			if !ok {
+				continue
+			}
+			// "15169 | 8.8.8.0/24 | US | arin | 2023-12-28"
+			origin, _, _ := strings.Cut(strings.Join(txt.TXT, ""), "|")
+			asns = append(asns, strings.Fields(origin)...)
+		}
+		return asns
+	})
+}
+
+// cymruName returns the Team Cymru origin query name of an address.
+func cymruName(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	if v4 := addr.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.%s", v4[3], v4[2], v4[1], v4[0], cymruOrigin4)
+	}
+	var b strings.Builder
+	for i := len(addr) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "%x.%x.", addr[i]&0xf, addr[i]>>4)
+	}
+	return b.String() + cymruOrigin6
+}
+
+// attribute compares the fingerprint of a variant with the baseline and
+// returns the ownership verdict with the signals that matched.
+func (s *Scanner) attribute(base, v *Fingerprint) (Ownership, []string) {
+	var signals []string
+	score := 0
+	add := func(weight int, format string, args ...interface{}) {
+		score += weight
+		signals = append(signals, fmt.Sprintf(format, args...))
+	}
+
+	// Infrastructure inside the original domain is only set up by its owner.
+	origin := s.suffixes.RegistrableDomain(base.Domain)
+	if v.CNAME != "" && s.suffixes.RegistrableDomain(v.CNAME) == origin {
+		add(3, "CNAME to %s", v.CNAME)
+	}
+	if hosts := within(v.NameServers, s.suffixes, origin); len(hosts) > 0 {
+		add(3, "name servers under %s: %s", origin, strings.Join(hosts, ", "))
+	} else if shared := intersect(base.NameServers, v.NameServers); len(shared) > 0 {
+		// Large DNS providers serve millions of zones from the same hosts,
+		// so only an identical set is a strong hint.
+		if len(shared) == len(base.NameServers) && len(shared) == len(v.NameServers) {
+			add(2, "same name servers: %s", strings.Join(shared, ", "))
+		} else {
+			add(1, "shared name servers: %s", strings.Join(shared, ", "))
+		}
+	}
+	if hosts := within(v.MX, s.suffixes, origin); len(hosts) > 0 {
+		add(3, "MX under %s: %s", origin, strings.Join(hosts, ", "))
+	} else if shared := intersect(base.MX, v.MX); len(shared) > 0 {
+		add(1, "shared MX: %s", strings.Join(shared, ", "))
+	}
+	if base.RegistrantOrg != "" && strings.EqualFold(normalizeOrg(base.RegistrantOrg), normalizeOrg(v.RegistrantOrg)) {
+		add(3, "same registrant organisation: %s", v.RegistrantOrg)
+	}
+	if base.Registrar != "" && strings.EqualFold(normalizeOrg(base.Registrar), normalizeOrg(v.Registrar)) {
+		add(1, "same registrar: %s", v.Registrar)
+	}
+	if shared := intersect(base.IPs, v.IPs); len(shared) > 0 {
+		add(1, "shared IPs: %s", strings.Join(shared, ", "))
+	}
+	// The same network alone says little; it is reported but not counted.
+	if shared := intersect(base.ASNs, v.ASNs); len(shared) > 0 {
+		add(0, "same ASN: AS%s", strings.Join(shared, ", AS"))
+	}
+
+	switch {
+	case score >= ownedScore:
+		return OwnershipLikelyOwned, signals
+	case score == 0 && !base.empty() && (len(v.NameServers) > 0 || v.RegistrantOrg != "" || v.Registrar != ""):
+		return OwnershipLikelyThirdParty, signals
+	default:
+		return OwnershipUnknown, signals
+	}
+}
+
+// within returns the hosts that belong to the registrable domain origin.
+func within(hosts []string, suffixes *SuffixList, origin string) []string {
+	var out []string
+	for _, h := range hosts {
+		if origin != "" && suffixes.RegistrableDomain(h) == origin {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// intersect returns the values present in both lists, case-insensitively.
+func intersect(a, b []string) []string {
+	set := make(map[string]struct{}, len(a))
+	for _, v := range a {
+		set[strings.ToLower(v)] = struct{}{}
+	}
+	shared := make(map[string]struct{})
+	for _, v := range b {
+		if _, ok := set[strings.ToLower(v)]; ok {
+			shared[strings.ToLower(v)] = struct{}{}
+		}
+	}
+	return sortedKeys(shared)
+}
+
+// normalizeOrg drops commas, dots and repeated spaces so "Example, Inc."
+// and "EXAMPLE INC" compare equal.
+func normalizeOrg(org string) string {
+	org = strings.Map(func(r rune) rune {
+		if r == ',' || r == '.' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(org))
+	return strings.Join(strings.Fields(org), " ")
+}
@@ -0,0 +1,50 @@
+package tldbuster
+
+import "sync"
+
+// suffixCache computes a value once per suffix and shares it between
+// workers. Concurrent callers for the same suffix wait for the first one.
+type suffixCache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry[T]
+}
+
+// cacheEntry holds a single lazily computed value.
+type cacheEntry[T any] struct {
+	once sync.Once
+	val  T
+}
+
+// get returns the cached value for suffix, computing it on first use.
+func (c *suffixCache[T]) get(suffix string, compute func() T) T {
+	return c.getUnlessFailed(suffix, func() (T, bool) { return compute(), true })
+}
+
+// getUnlessFailed is get for computations that can fail: a value compute
+// reports as failed is handed to the callers already waiting for it, then
+// forgotten so the next call computes it again.
+func (c *suffixCache[T]) getUnlessFailed(suffix string, compute func() (T, bool)) T {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]*cacheEntry[T])
+	}
+	entry, ok := c.entries[suffix]
+	if !ok {
+		entry = &cacheEntry[T]{}
+		c.entries[suffix] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		var ok bool
+		entry.val, ok = compute()
+		if !ok {
+			c.mu.Lock()
+			if c.entries[suffix] == entry {
+				delete(c.entries, suffix)
+			}
+			c.mu.Unlock()
+		}
+	})
+	return entry.val
+}
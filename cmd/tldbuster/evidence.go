@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/r3dcl1ff/TLDBuster"
+)
+
+// evidenceFile describes one file of an evidence directory. Files derived
+// from another one, like decoded WHOIS text, name their source.
+type evidenceFile struct {
+	Name        string     `json:"name"`
+	DerivedFrom string     `json:"derived_from,omitempty"`
+	Server      string     `json:"server,omitempty"`
+	Query       string     `json:"query,omitempty"`
+	Time        *time.Time `json:"time,omitempty"`
+	SHA256      string     `json:"sha256"`
+}
+
+// evidenceManifest indexes an evidence directory. The hashes let a later
+// reader verify that the files were not altered.
+type evidenceManifest struct {
+	Domain    string         `json:"domain"`
+	Status    string         `json:"status"`
+	Collected time.Time      `json:"collected"`
+	Files     []evidenceFile `json:"files"`
+}
+
+// writeEvidence stores the evidence of a result in dir/<domain>: the WHOIS
+// and RDAP responses byte for byte as received, WHOIS text decoded to UTF-8
+// where the raw bytes differ, the DNS answers in zone file format and a
+// manifest with the query times and SHA-256 hashes of every file.
+func writeEvidence(dir string, result tldbuster.Result) error {
+	ev := result.Evidence
+	if ev == nil {
+		return nil
+	}
+	domainDir := filepath.Join(dir, result.Domain)
+	if err := os.MkdirAll(domainDir, 0o755); err != nil {
+		return err
+	}
+	manifest := evidenceManifest{
+		Domain:    result.Domain,
+		Status:    string(result.Status),
+		Collected: time.Now().UTC(),
+	}
+	write := func(f evidenceFile, data []byte) error {
+		sum := sha256.Sum256(data)
+		f.SHA256 = hex.EncodeToString(sum[:])
+		manifest.Files = append(manifest.Files, f)
+		return os.WriteFile(filepath.Join(domainDir, f.Name), data, 0o644)
+	}
+
+	if len(ev.DNS) > 0 {
+		var buf bytes.Buffer
+		for _, q := range ev.DNS {
+			via := "resolver"
+			if q.Server != "" {
+				via = q.Server
+			}
+			fmt.Fprintf(&buf, "; %s %s %s via %s: %s\n", q.Time.Format(time.RFC3339Nano), q.Name, q.Type, via, q.Status)
+			for _, rr := range q.Answers {
+				fmt.Fprintln(&buf, rr)
+			}
+			for _, rr := range q.Authorities {
+				fmt.Fprintf(&buf, "%s\t; authority\n", rr)
+			}
+			fmt.Fprintln(&buf)
+		}
+		if err := write(evidenceFile{Name: "dns.txt"}, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	for i, w := range ev.Whois {
+		name := fmt.Sprintf("whois-%d.raw", i+1)
+		if strings.HasPrefix(w.Server, "http") {
+			name = fmt.Sprintf("rdap-%d.json", i+1)
+		}
+		sent := w.Time
+		f := evidenceFile{Name: name, Server: w.Server, Query: w.Query, Time: &sent}
+		if err := write(f, w.Raw); err != nil {
+			return err
+		}
+		if w.Response != "" {
+			derived := evidenceFile{Name: fmt.Sprintf("whois-%d.txt", i+1), DerivedFrom: name}
+			if err := write(derived, []byte(w.Response)); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(domainDir, "manifest.json"), data, 0o644)
+}
@@ -0,0 +1,423 @@
+// Command tldbuster discovers domain variants across different TLDs.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/r3dcl1ff/TLDBuster"
+)
+
+func main() {
+	// Define command-line flags.
+	domain := flag.String("d", "", "Domain to test against (single target)")
+	domainList := flag.String("dL", "", "List of targets (e.g., targets.txt)")
+	silent := flag.Bool("s", false, "Silent output")
+	verbose := flag.Bool("v", false, "Verbose output")
+	outputFile := flag.String("o", "", "Output file (json or txt)")
+	debug := flag.Bool("debug", false, "Debugging mode")
+	concurrency := flag.Int("c", tldbuster.DefaultConcurrency, "Number of concurrent workers")
+	pslFile := flag.String("psl", "", "Public Suffix List file (defaults to the embedded copy)")
+	pslICANN := flag.Bool("psl-icann", false, "Ignore the private section of the Public Suffix List")
+	secondLevel := flag.Bool("sld", false, "Also try common second-level ccTLD suffixes (co.uk, com.br, ...)")
+	secondLevelMin := flag.Int("sld-min", tldbuster.DefaultSecondLevelMin, "Minimum number of ccTLDs a second-level label must appear under to be tried")
+	wildcard := flag.String("wildcard", "drop", "Handling of wildcard DNS matches: drop, flag or off")
+	resolvers := flag.String("r", "", "Comma-separated DNS resolvers (defaults to /etc/resolv.conf)")
+	retries := flag.Int("retries", tldbuster.DefaultRetries, "Retries for DNS lookups that time out or fail with SERVFAIL")
+	delegation := flag.Bool("ns", false, "Check NS delegation at each TLD's authoritative servers")
+	records := flag.Bool("records", true, "Collect NS, MX, TXT, CNAME, SOA and CAA records of discovered domains")
+	whoisCache := flag.String("whois-cache", tldbuster.DefaultWhoisCachePath(), "File caching the WHOIS server of each TLD (empty to disable)")
+	whoisServers := flag.String("whois-servers", "", "File of \"tld server\" lines overriding WHOIS server discovery")
+	whoisIANA := flag.String("whois-iana", "", "WHOIS server asked for TLD referrals (defaults to whois.iana.org)")
+	whoisOptions := flag.String("whois-options", "", "File of \"server charset [query template]\" lines for WHOIS servers")
+	whoisPerServer := flag.Int("whois-per-server", tldbuster.DefaultWhoisPerServer, "Concurrent queries allowed per WHOIS server (0 for no limit)")
+	whoisInterval := flag.Duration("whois-interval", tldbuster.DefaultWhoisInterval, "Minimum delay between queries to the same WHOIS server")
+	whoisRetries := flag.Int("whois-retries", tldbuster.DefaultWhoisRetries, "Retries, with exponential backoff, for rate-limited WHOIS queries")
+	permutations := flag.String("perm", "", "Comma-separated label permutation algorithms, or \"all\" ("+strings.Join(tldbuster.Algorithms(), ", ")+")")
+	keyboards := flag.String("keyboards", "qwerty,qwertz,azerty", "Keyboard layouts for adjacent-key permutations")
+	confusables := flag.String("confusables", "", "Unicode confusables.txt file replacing the embedded lookalikes of the homograph algorithm")
+	idnPolicy := flag.String("idn-policy", "registry", "Suffixes tried for IDN variants: registry (scripts each TLD accepts) or off (all)")
+	keywords := flag.String("keywords", "", "Keyword file for combosquatting, one word per line (defaults to built-in auth, support, payment and region words)")
+	subdomains := flag.String("subdomains", "", "Subdomain wordlist for doppelgangers, one per line (defaults to built-in mail and common hosts)")
+	phoneticThreshold := flag.Float64("phonetic-threshold", tldbuster.DefaultPhoneticThreshold, "Minimum similarity (0-1) of phonetic variants to the original name")
+	tldTypos := flag.String("tld-typos", "off", "Typo TLDs of the original TLD (.co, .cm, .con for .com): off, rank (tried first) or only")
+	permTLDs := flag.String("perm-tlds", "", "Comma-separated suffixes crossed with permuted labels (defaults to all candidates)")
+	allowlistFile := flag.String("allowlist", "", "File of known-owned domains, name server patterns, registrars and registrant orgs")
+	allowlistMode := flag.String("allowlist-mode", "suppress", "Handling of allowlisted results: suppress or tag")
+	attribution := flag.Bool("attribution", true, "Classify variants as likely owned or third-party against the original domain's fingerprint")
+	evidence := flag.Bool("evidence", false, "Embed raw DNS answers and WHOIS/RDAP responses with query times in the JSON output")
+	evidenceDir := flag.String("evidence-dir", "", "Directory receiving the raw DNS and WHOIS evidence of each result")
+	rdapMode := flag.String("rdap", "fallback", "RDAP usage: off, fallback (when WHOIS comes back empty) or only")
+	rdapBootstrap := flag.String("rdap-bootstrap", "", "RDAP bootstrap file (dns.json) replacing the embedded copy")
+	flag.Parse()
+
+	if !*silent {
+		printBanner()
+	}
+
+	// Validate input.
+	if (*domain == "" && *domainList == "") || (*domain != "" && *domainList != "") {
+		fmt.Println("Please specify either -d or -dL, but not both.")
+		os.Exit(1)
+	}
+
+	// Load domains to test.
+	var domains []string
+	if *domain != "" {
+		domains = append(domains, *domain)
+	} else {
+		var err error
+		domains, err = readLines(*domainList)
+		if err != nil {
+			log.Fatalf("Error reading file: %v", err)
+		}
+	}
+
+	wildcardPolicy, err := parseWildcardPolicy(*wildcard)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suffixes := tldbuster.DefaultSuffixList()
+	if *pslFile != "" {
+		if suffixes, err = tldbuster.LoadSuffixListFile(*pslFile); err != nil {
+			log.Fatalf("Error loading public suffix list: %v", err)
+		}
+	}
+	if *pslICANN {
+		icannOnly := *suffixes
+		icannOnly.ICANNOnly = true
+		suffixes = &icannOnly
+	}
+
+	algorithms, err := parseAlgorithms(*permutations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var layouts []tldbuster.Keyboard
+	for _, kb := range splitList(strings.ToLower(*keyboards)) {
+		layout := tldbuster.Keyboard(kb)
+		if !slices.Contains(tldbuster.DefaultKeyboards, layout) {
+			log.Fatalf("unknown keyboard layout %q", kb)
+		}
+		layouts = append(layouts, layout)
+	}
+	var lookalikes tldbuster.Confusables
+	if *confusables != "" {
+		if lookalikes, err = tldbuster.LoadConfusablesFile(*confusables); err != nil {
+			log.Fatalf("Error loading confusables: %v", err)
+		}
+	}
+	var words []string
+	if *keywords != "" {
+		if words, err = readWordlist(*keywords); err != nil {
+			log.Fatalf("Error reading keywords: %v", err)
+		}
+	}
+	var hosts []string
+	if *subdomains != "" {
+		if hosts, err = readWordlist(*subdomains); err != nil {
+			log.Fatalf("Error reading subdomains: %v", err)
+		}
+	}
+	if *phoneticThreshold <= 0 || *phoneticThreshold > 1 {
+		log.Fatalf("phonetic threshold must be in (0, 1], got %v", *phoneticThreshold)
+	}
+	if *idnPolicy != "registry" && *idnPolicy != "off" {
+		log.Fatalf("unknown IDN policy %q", *idnPolicy)
+	}
+
+	var allowlist *tldbuster.Allowlist
+	if *allowlistFile != "" {
+		if allowlist, err = tldbuster.LoadAllowlistFile(*allowlistFile); err != nil {
+			log.Fatalf("Error loading allowlist: %v", err)
+		}
+	}
+	allowlistPolicy, err := parseAllowlistPolicy(*allowlistMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+	typoMode, err := parseTLDTypoMode(*tldTypos)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	whois := tldbuster.NewWhoisClient(*whoisCache)
+	whois.IANAServer = *whoisIANA
+	whois.MaxPerServer = *whoisPerServer
+	whois.Interval = *whoisInterval
+	whois.Retries = *whoisRetries
+	if *whoisServers != "" {
+		if whois.Overrides, err = readServerMap(*whoisServers); err != nil {
+			log.Fatalf("Error reading WHOIS servers: %v", err)
+		}
+	}
+	if *whoisOptions != "" {
+		if whois.Charsets, whois.QueryTemplates, err = readWhoisOptions(*whoisOptions); err != nil {
+			log.Fatalf("Error reading WHOIS options: %v", err)
+		}
+	}
+
+	rdap := tldbuster.NewRDAPClient()
+	if *rdapBootstrap != "" {
+		if rdap.Bootstrap, err = tldbuster.LoadRDAPBootstrapFile(*rdapBootstrap); err != nil {
+			log.Fatalf("Error loading RDAP bootstrap: %v", err)
+		}
+	}
+	var whoisClient tldbuster.WhoisClient
+	switch strings.ToLower(*rdapMode) {
+	case "off":
+		whoisClient = whois
+	case "fallback":
+		whoisClient = &tldbuster.FallbackWhois{Primary: whois, Fallback: rdap}
+	case "only":
+		whoisClient = rdap
+	default:
+		log.Fatalf("unknown RDAP mode %q", *rdapMode)
+	}
+
+	opts := []tldbuster.Option{
+		tldbuster.WithConcurrency(*concurrency),
+		tldbuster.WithSuffixList(suffixes),
+		tldbuster.WithWildcardPolicy(wildcardPolicy),
+		tldbuster.WithAllowlist(allowlist, allowlistPolicy),
+		tldbuster.WithPermutations(algorithms...),
+		tldbuster.WithKeyboards(layouts...),
+		tldbuster.WithConfusables(lookalikes),
+		tldbuster.WithKeywords(words),
+		tldbuster.WithSubdomains(hosts),
+		tldbuster.WithPhoneticThreshold(*phoneticThreshold),
+		tldbuster.WithTLDTypos(typoMode),
+		tldbuster.WithIDNPolicy(*idnPolicy == "registry"),
+		tldbuster.WithResolver(tldbuster.NewResolver(splitList(*resolvers)...)),
+		tldbuster.WithRetries(*retries),
+		tldbuster.WithDelegationCheck(*delegation),
+		tldbuster.WithRecords(*records),
+		tldbuster.WithWhoisClient(whoisClient),
+		tldbuster.WithAttribution(*attribution),
+		tldbuster.WithEvidence(*evidence || *evidenceDir != ""),
+	}
+	if *permTLDs != "" {
+		opts = append(opts, tldbuster.WithPermutationTLDs(splitList(strings.ToLower(*permTLDs))))
+	}
+	if *secondLevel {
+		opts = append(opts, tldbuster.WithSecondLevel(*secondLevelMin))
+	}
+	if !*silent {
+		opts = append(opts, tldbuster.WithResultHandler(printResult))
+	}
+	if *debug {
+		opts = append(opts, tldbuster.WithDebugLog(log.Default()))
+	}
+	if *verbose {
+		opts = append(opts, tldbuster.WithVerboseLog(log.Default()))
+	}
+	scanner := tldbuster.New(opts...)
+
+	// Process each input domain.
+	ctx := context.Background()
+	var results []tldbuster.Result
+	for _, domainName := range domains {
+		res, err := scanner.Scan(ctx, domainName)
+		if err != nil {
+			if *debug || !errors.Is(err, tldbuster.ErrNotFound) && !errors.Is(err, tldbuster.ErrNoBaseName) {
+				log.Printf("Error scanning %s: %v", domainName, err)
+			}
+		}
+		results = append(results, res...)
+	}
+
+	if *evidenceDir != "" {
+		for i := range results {
+			if err := writeEvidence(*evidenceDir, results[i]); err != nil {
+				log.Printf("Error writing evidence of %s: %v", results[i].Domain, err)
+			}
+			if !*evidence {
+				results[i].Evidence = nil
+			}
+		}
+	}
+
+	// Output results.
+	if !*silent {
+		for _, result := range results {
+			printResult(result)
+		}
+		if n := scanner.Suppressed(); n > 0 {
+			fmt.Printf("Suppressed %d allowlisted result(s).\n", n)
+		}
+	} else if n := scanner.Suppressed(); n > 0 {
+		// Silent runs still report the count, which JSON files do not hold.
+		fmt.Fprintf(os.Stderr, "Suppressed %d allowlisted result(s).\n", n)
+	}
+	if *outputFile != "" {
+		if err := outputResults(results, scanner.Suppressed(), *outputFile); err != nil {
+			log.Printf("Error writing output: %v", err)
+		}
+	}
+}
+
+// parseAlgorithms validates the -perm flag. "all" selects every algorithm.
+func parseAlgorithms(s string) ([]string, error) {
+	names := splitList(strings.ToLower(s))
+	if len(names) == 1 && names[0] == "all" {
+		return tldbuster.Algorithms(), nil
+	}
+	known := make(map[string]bool)
+	for _, name := range tldbuster.Algorithms() {
+		known[name] = true
+	}
+	for _, name := range names {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown permutation algorithm %q", name)
+		}
+	}
+	return names, nil
+}
+
+// parseAllowlistPolicy maps the -allowlist-mode flag to an AllowlistPolicy.
+func parseAllowlistPolicy(s string) (tldbuster.AllowlistPolicy, error) {
+	switch strings.ToLower(s) {
+	case "suppress":
+		return tldbuster.AllowlistSuppress, nil
+	case "tag":
+		return tldbuster.AllowlistTag, nil
+	}
+	return 0, fmt.Errorf("unknown allowlist mode %q", s)
+}
+
+// parseTLDTypoMode maps the -tld-typos flag to a TLDTypoMode.
+func parseTLDTypoMode(s string) (tldbuster.TLDTypoMode, error) {
+	switch strings.ToLower(s) {
+	case "off":
+		return tldbuster.TLDTyposOff, nil
+	case "rank":
+		return tldbuster.TLDTyposRank, nil
+	case "only":
+		return tldbuster.TLDTyposOnly, nil
+	}
+	return 0, fmt.Errorf("unknown TLD typo mode %q", s)
+}
+
+// parseWildcardPolicy maps the -wildcard flag to a WildcardPolicy.
+func parseWildcardPolicy(s string) (tldbuster.WildcardPolicy, error) {
+	switch strings.ToLower(s) {
+	case "drop":
+		return tldbuster.WildcardDrop, nil
+	case "flag":
+		return tldbuster.WildcardFlag, nil
+	case "off":
+		return tldbuster.WildcardOff, nil
+	}
+	return 0, fmt.Errorf("unknown wildcard policy %q", s)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readServerMap reads "tld server" lines into a map, skipping # comments.
+func readServerMap(path string) (map[string]string, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	servers := make(map[string]string)
+	for _, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		servers[strings.ToLower(strings.TrimPrefix(fields[0], "."))] = fields[1]
+	}
+	return servers, nil
+}
+
+// readWhoisOptions reads "server charset [query template]" lines, skipping
+// # comments. A charset of "auto" keeps detection on for the server.
+func readWhoisOptions(path string) (charsets, templates map[string]string, err error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	charsets = make(map[string]string)
+	templates = make(map[string]string)
+	for _, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, nil, fmt.Errorf("malformed line %q", line)
+		}
+		server := strings.ToLower(fields[0])
+		charsets[server] = fields[1]
+		if len(fields) > 2 {
+			templates[server] = strings.Join(fields[2:], " ")
+		}
+	}
+	return charsets, templates, nil
+}
+
+// readWordlist reads one lowercased word per line, skipping # comments.
+func readWordlist(path string) ([]string, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	var words []string
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "#") {
+			words = append(words, strings.ToLower(line))
+		}
+	}
+	return words, nil
+}
+
+// readLines returns the non-empty, trimmed lines of a file.
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// printBanner displays the tool banner.
+func printBanner() {
+	cyan := "\033[36m"
+	reset := "\033[0m"
+	banner := `
+ ========================================
+   TLDBuster by r3dcl1ff @Redflare-Cyber
+ ========================================
+`
+	fmt.Printf("%s%s%s\n", cyan, banner, reset)
+}
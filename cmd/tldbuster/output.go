@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r3dcl1ff/TLDBuster"
+)
+
+// printMu serialises result printing across scanner workers.
+var printMu sync.Mutex
+
+// printResult writes a single result to stdout.
+func printResult(result tldbuster.Result) {
+	printMu.Lock()
+	defer printMu.Unlock()
+	writeResult(os.Stdout, result, true)
+}
+
+// writeResult writes a result in the plain text format. When colored is
+// set, the domain line and mail doppelgangers are highlighted for terminals.
+func writeResult(w io.Writer, result tldbuster.Result, colored bool) {
+	if colored {
+		fmt.Fprintf(w, "\033[31mDomain: %s\033[0m\n", result.Domain)
+	} else {
+		fmt.Fprintf(w, "Domain: %s\n", result.Domain)
+	}
+	if result.UnicodeDomain != "" {
+		fmt.Fprintf(w, "Unicode: %s\n", result.UnicodeDomain)
+	}
+	fmt.Fprintf(w, "Status: %s\n", result.Status)
+	if result.Algorithm != "" && result.Algorithm != tldbuster.AlgorithmTLDSwap {
+		fmt.Fprintf(w, "Algorithm: %s\n", result.Algorithm)
+	}
+	if result.MailDoppelganger {
+		if colored {
+			fmt.Fprintf(w, "\033[33mMail doppelganger: may receive misdirected email\033[0m\n")
+		} else {
+			fmt.Fprintf(w, "Mail doppelganger: may receive misdirected email\n")
+		}
+	}
+	if result.Delegation != "" {
+		fmt.Fprintf(w, "Delegation: %s %v\n", result.Delegation, result.DelegationNS)
+	}
+	fmt.Fprintf(w, "IPs: %v\n", result.IPs)
+	if result.Records != nil {
+		writeRecords(w, result.Records)
+	}
+	fmt.Fprintf(w, "Registrant: %s\n", result.Registrant)
+	if result.Privacy != "" {
+		fmt.Fprintf(w, "Registrant Privacy: %s\n", joinNonEmpty(string(result.Privacy), result.PrivacyService))
+	}
+	fmt.Fprintf(w, "Server: %s\n", result.Server)
+	if result.Whois != nil {
+		writeWhois(w, result.Whois)
+	}
+	if result.WhoisIncomplete {
+		fmt.Fprintf(w, "WHOIS: incomplete %s\n", result.WhoisError)
+	}
+	if result.Ownership != "" {
+		fmt.Fprintf(w, "Ownership: %s\n", result.Ownership)
+		for _, signal := range result.OwnershipSignals {
+			fmt.Fprintf(w, "  - %s\n", signal)
+		}
+	}
+	if result.Reason != "" {
+		fmt.Fprintf(w, "Note: %s\n", result.Reason)
+	}
+	fmt.Fprintln(w)
+}
+
+// writeRecords writes the collected DNS records, one line per type.
+func writeRecords(w io.Writer, recs *tldbuster.DNSRecords) {
+	if len(recs.NS) > 0 {
+		fmt.Fprintf(w, "NS: %s\n", strings.Join(recs.NS, ", "))
+	}
+	if len(recs.MX) > 0 {
+		var mx []string
+		for _, rec := range recs.MX {
+			mx = append(mx, fmt.Sprintf("%d %s", rec.Preference, rec.Host))
+		}
+		fmt.Fprintf(w, "MX: %s\n", strings.Join(mx, ", "))
+	}
+	for _, txt := range recs.TXT {
+		fmt.Fprintf(w, "TXT: %q\n", txt)
+	}
+	if recs.CNAME != "" {
+		fmt.Fprintf(w, "CNAME: %s\n", recs.CNAME)
+	}
+	if soa := recs.SOA; soa != nil {
+		fmt.Fprintf(w, "SOA: %s %s %d %d %d %d %d\n", soa.NS, soa.MBox, soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.MinTTL)
+	}
+	for _, caa := range recs.CAA {
+		fmt.Fprintf(w, "CAA: %d %s %q\n", caa.Flag, caa.Tag, caa.Value)
+	}
+}
+
+// writeWhois writes the registration details beyond registrant and registrar.
+func writeWhois(w io.Writer, rec *tldbuster.WhoisRecord) {
+	fmt.Fprintf(w, "Source: %s %s\n", rec.Source, rec.Server)
+	if rec.RegistrantOrg != "" || rec.RegistrantCountry != "" || rec.RegistrantEmail != "" {
+		fmt.Fprintf(w, "Registrant Details: %s\n", joinNonEmpty(rec.RegistrantOrg, rec.RegistrantCountry, rec.RegistrantEmail))
+	}
+	if rec.AbuseEmail != "" || rec.AbusePhone != "" {
+		fmt.Fprintf(w, "Abuse Contact: %s\n", joinNonEmpty(rec.AbuseEmail, rec.AbusePhone))
+	}
+	if rec.Created != nil {
+		fmt.Fprintf(w, "Created: %s (%d days old)\n", rec.Created.Format(time.RFC3339), int(rec.Age(time.Now()).Hours()/24))
+	}
+	if rec.Updated != nil {
+		fmt.Fprintf(w, "Updated: %s\n", rec.Updated.Format(time.RFC3339))
+	}
+	if rec.Expires != nil {
+		fmt.Fprintf(w, "Expires: %s\n", rec.Expires.Format(time.RFC3339))
+	}
+	if len(rec.Status) > 0 {
+		fmt.Fprintf(w, "Domain Status: %s (transfer locked: %t)\n", strings.Join(rec.Status, ", "), rec.TransferLocked())
+	}
+	if len(rec.NameServers) > 0 {
+		fmt.Fprintf(w, "Name Servers: %s\n", strings.Join(rec.NameServers, ", "))
+	}
+}
+
+// joinNonEmpty joins the non-empty values with commas.
+func joinNonEmpty(values ...string) string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// outputResults writes the results to a file in JSON or plain text format.
+// The text format ends with the number of suppressed allowlisted results;
+// JSON files hold the bare array of results.
+func outputResults(results []tldbuster.Result, suppressed int, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if strings.HasSuffix(strings.ToLower(filename), ".json") {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+
+	// TXT format.
+	w := bufio.NewWriter(file)
+	for _, result := range results {
+		writeResult(w, result, false)
+	}
+	if suppressed > 0 {
+		fmt.Fprintf(w, "Suppressed %d allowlisted result(s).\n", suppressed)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,117 @@
+package tldbuster
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DelegationStatus is the outcome of asking a suffix's authoritative
+// servers for the NS delegation of a candidate.
+type DelegationStatus string
+
+const (
+	// DelegationFound means the registry delegates the candidate, so it is
+	// registered whether or not it resolves.
+	DelegationFound DelegationStatus = "delegated"
+	// DelegationNone means the registry holds no delegation for the candidate.
+	DelegationNone DelegationStatus = "not-delegated"
+	// DelegationUnknown means none of the authoritative servers answered.
+	DelegationUnknown DelegationStatus = "unknown"
+)
+
+// Exchanger sends a single query to a specific server. DNSResolver
+// satisfies it.
+type Exchanger interface {
+	Exchange(ctx context.Context, server, name string, qtype dnsmessage.Type, recursive bool) (*Response, error)
+}
+
+// maxParentServers caps how many authoritative servers are kept per suffix.
+const maxParentServers = 4
+
+// parentServers returns the addresses of the authoritative name servers of
+// suffix, or of the closest enclosing zone when suffix is not a zone cut of
+// its own (e.g. a second-level suffix served from its TLD zone).
+func (s *Scanner) parentServers(ctx context.Context, suffix string) []string {
+	return s.parents.get(suffix, func() []string {
+		ctx := withEvidence(ctx, nil)
+		for zone := suffix; zone != ""; zone = parentZone(zone) {
+			resp, status := s.query(ctx, zone, dnsmessage.TypeNS)
+			if status != StatusResolved {
+				continue
+			}
+			var servers []string
+			for _, host := range nsHosts(resp.Answers, zone) {
+				_, ips := s.lookupAddrs(ctx, host, dnsmessage.TypeA)
+				for _, ip := range ips {
+					servers = append(servers, net.JoinHostPort(ip, "53"))
+				}
+				if len(servers) >= maxParentServers {
+					break
+				}
+			}
+			if len(servers) > 0 {
+				s.debugf("Authoritative servers for .%s (zone %s): %s", suffix, zone, strings.Join(servers, ", "))
+				return servers
+			}
+		}
+		s.debugf("No authoritative servers found for .%s", suffix)
+		return nil
+	})
+}
+
+// checkDelegation asks the authoritative servers of suffix whether they
+// delegate domain, returning the delegated name servers if so.
+func (s *Scanner) checkDelegation(ctx context.Context, domain, suffix string) (DelegationStatus, []string) {
+	servers := s.parentServers(ctx, suffix)
+	for _, server := range servers {
+		ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+		sent := time.Now()
+		resp, err := s.exchanger.Exchange(ctx, server, domain, dnsmessage.TypeNS, false)
+		cancel()
+		if err != nil {
+			evidenceFrom(ctx).addDNS(sent, domain, dnsmessage.TypeNS, server, errorStatus(err), nil)
+			s.debugf("Delegation query for %s at %s failed: %v", domain, server, err)
+			continue
+		}
+		evidenceFrom(ctx).addDNS(sent, domain, dnsmessage.TypeNS, server, resp.Status, resp)
+		switch resp.Status {
+		case StatusNXDomain:
+			return DelegationNone, nil
+		case StatusResolved, StatusNoData:
+			// A referral lists the delegation in the authority section; a
+			// server that is also authoritative for the child answers directly.
+			hosts := nsHosts(resp.Authorities, domain)
+			hosts = append(hosts, nsHosts(resp.Answers, domain)...)
+			if len(hosts) > 0 {
+				return DelegationFound, hosts
+			}
+			return DelegationNone, nil
+		}
+	}
+	return DelegationUnknown, nil
+}
+
+// nsHosts returns the targets of the NS records owned by name.
+func nsHosts(records []dnsmessage.Resource, name string) []string {
+	var hosts []string
+	for _, rr := range records {
+		ns, ok := rr.Body.(*dnsmessage.NSResource)
+		if !ok || !strings.EqualFold(rr.Header.Name.String(), dnsName(name)) {
+			continue
+		}
+		hosts = append(hosts, hostName(ns.NS))
+	}
+	return hosts
+}
+
+// parentZone strips the leftmost label of a name.
+func parentZone(name string) string {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
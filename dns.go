@@ -0,0 +1,88 @@
+package tldbuster
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// lookupTimeout bounds every individual DNS lookup, retries included.
+const lookupTimeout = 10 * time.Second
+
+// DefaultRetries is the number of times a lookup that timed out or failed
+// with SERVFAIL is retried.
+const DefaultRetries = 2
+
+// retryBackoff is the delay before the first retry; it doubles each time.
+const retryBackoff = 250 * time.Millisecond
+
+// checkDomain resolves the A and AAAA records of domain and classifies the
+// outcome. NODATA on both types still proves the name exists.
+func (s *Scanner) checkDomain(ctx context.Context, domain string) (LookupStatus, []string) {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	status, ipStrs := s.lookupAddrs(ctx, domain, dnsmessage.TypeA)
+	if status == StatusNXDomain {
+		return status, nil
+	}
+	status6, ips6 := s.lookupAddrs(ctx, domain, dnsmessage.TypeAAAA)
+	ipStrs = append(ipStrs, ips6...)
+	switch {
+	case status == StatusResolved || status6 == StatusResolved:
+		return StatusResolved, ipStrs
+	case status == StatusNoData || status6 == StatusNoData:
+		return StatusNoData, nil
+	}
+	return status, nil
+}
+
+// lookupAddrs queries one address type and returns the addresses found.
+func (s *Scanner) lookupAddrs(ctx context.Context, domain string, qtype dnsmessage.Type) (LookupStatus, []string) {
+	sent := time.Now()
+	resp, status := s.query(ctx, domain, qtype)
+	evidenceFrom(ctx).addDNS(sent, domain, qtype, "", status, resp)
+	if resp == nil {
+		return status, nil
+	}
+	var ipStrs []string
+	for _, rr := range resp.Answers {
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			ipStrs = append(ipStrs, net.IP(body.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			ipStrs = append(ipStrs, net.IP(body.AAAA[:]).String())
+		}
+	}
+	return status, ipStrs
+}
+
+// query runs a lookup through the resolver, retrying timeouts and SERVFAIL
+// with exponential backoff.
+func (s *Scanner) query(ctx context.Context, name string, qtype dnsmessage.Type) (*Response, LookupStatus) {
+	backoff := retryBackoff
+	var resp *Response
+	var status LookupStatus
+	for attempt := 0; ; attempt++ {
+		var err error
+		resp, err = s.resolver.Query(ctx, name, qtype)
+		if err != nil {
+			resp, status = nil, errorStatus(err)
+		} else {
+			status = resp.Status
+		}
+		if (status != StatusTimeout && status != StatusServFail) || attempt >= s.retries {
+			break
+		}
+		s.debugf("Lookup %s %s: %s, retrying", name, qtype, status)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return resp, status
+		}
+		backoff *= 2
+	}
+	return resp, status
+}
@@ -0,0 +1,18 @@
+package tldbuster
+
+import "strings"
+
+// extractBaseName returns the base name and public suffix of a given
+// domain, so "www.example.co.uk" yields ("example", "co.uk").
+func (s *Scanner) extractBaseName(domain string) (string, string) {
+	return s.suffixes.Split(domain)
+}
+
+// getTLD extracts the TLD from a domain.
+func getTLD(domain string) string {
+	parts := strings.Split(domain, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
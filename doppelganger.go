@@ -0,0 +1,50 @@
+package tldbuster
+
+import "strings"
+
+// DefaultSubdomains are the host names the doppelganger algorithm joins to
+// the label when none are configured: mail hosts first, then common ones.
+var DefaultSubdomains = []string{
+	"mail", "email", "webmail", "smtp", "imap", "pop", "pop3", "mx", "owa", "exchange", "autodiscover",
+	"www", "vpn", "remote", "portal", "login", "sso", "intranet", "extranet", "shop",
+}
+
+// mailSubdomains are the host names whose doppelgangers catch misdirected
+// email.
+var mailSubdomains = map[string]bool{
+	"mail": true, "email": true, "webmail": true, "smtp": true, "imap": true, "pop": true,
+	"pop3": true, "mx": true, "mx1": true, "mx2": true, "owa": true, "exchange": true,
+	"autodiscover": true, "outlook": true, "mailhost": true, "relay": true,
+}
+
+// doppelganger joins each subdomain to the label with the dot dropped or
+// replaced by a hyphen: "mail.example" -> "mailexample", "mail-example".
+func doppelganger(label string, data *permuteData) []string {
+	var out []string
+	for _, sub := range data.subdomains {
+		sub = strings.ToLower(sub)
+		out = append(out, sub+label, sub+"-"+label)
+	}
+	return out
+}
+
+// mailDoppelganger reports whether variant joins a mail host name to label.
+func mailDoppelganger(variant, label string) bool {
+	sub := strings.TrimSuffix(strings.TrimSuffix(variant, label), "-")
+	return sub != variant && mailSubdomains[sub]
+}
+
+// inputSubdomains returns the subdomain labels of domain below registrable,
+// plus their concatenation when there are several: "smtp.mail" gives
+// "smtp", "mail" and "smtpmail".
+func inputSubdomains(domain, registrable string) []string {
+	sub := strings.TrimSuffix(domain, "."+registrable)
+	if sub == domain || sub == "" {
+		return nil
+	}
+	subs := strings.Split(sub, ".")
+	if len(subs) > 1 {
+		subs = append(subs, strings.Join(subs, ""))
+	}
+	return subs
+}
@@ -0,0 +1,164 @@
+package tldbuster
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Evidence holds the raw answers a result is based on, with the time each
+// query was sent, so findings can be cited after the run.
+type Evidence struct {
+	DNS   []DNSEvidence   `json:"dns,omitempty"`
+	Whois []WhoisEvidence `json:"whois,omitempty"`
+}
+
+// DNSEvidence is a single DNS query and its answer. Records are given in
+// zone file presentation format.
+type DNSEvidence struct {
+	Time time.Time `json:"time"`
+	Name string    `json:"name"`
+	Type string    `json:"type"`
+	// Server is set for queries sent to a specific authoritative server;
+	// other queries went through the configured resolvers.
+	Server      string       `json:"server,omitempty"`
+	Status      LookupStatus `json:"status"`
+	Answers     []string     `json:"answers,omitempty"`
+	Authorities []string     `json:"authorities,omitempty"`
+}
+
+// WhoisEvidence is a single WHOIS or RDAP exchange and its response.
+type WhoisEvidence struct {
+	Time time.Time `json:"time"`
+	// Server is the WHOIS server or the RDAP URL queried.
+	Server string `json:"server"`
+	Query  string `json:"query,omitempty"`
+	// Raw is the response exactly as received, in the server's charset.
+	Raw []byte `json:"raw"`
+	// Response is the response decoded to UTF-8 with LF line endings. It
+	// is only set when it differs from Raw.
+	Response string `json:"response,omitempty"`
+}
+
+// evidenceKey is the context key of the evidence log of a task.
+type evidenceKey struct{}
+
+// evidenceLog collects the evidence of a single task. A nil log discards
+// everything, so lookups can record unconditionally.
+type evidenceLog struct {
+	mu sync.Mutex
+	ev Evidence
+}
+
+// withEvidence attaches log to ctx. A nil log detaches any log, which keeps
+// lookups shared between tasks, like wildcard probes, out of the evidence.
+func withEvidence(ctx context.Context, log *evidenceLog) context.Context {
+	return context.WithValue(ctx, evidenceKey{}, log)
+}
+
+// evidenceFrom returns the log attached to ctx, if any.
+func evidenceFrom(ctx context.Context) *evidenceLog {
+	log, _ := ctx.Value(evidenceKey{}).(*evidenceLog)
+	return log
+}
+
+// addDNS records a DNS answer received at sent.
+func (l *evidenceLog) addDNS(sent time.Time, name string, qtype dnsmessage.Type, server string, status LookupStatus, resp *Response) {
+	if l == nil {
+		return
+	}
+	ev := DNSEvidence{Time: sent.UTC(), Name: name, Type: typeName(qtype), Server: server, Status: status}
+	if resp != nil {
+		for _, rr := range resp.Answers {
+			ev.Answers = append(ev.Answers, formatRR(rr))
+		}
+		for _, rr := range resp.Authorities {
+			ev.Authorities = append(ev.Authorities, formatRR(rr))
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ev.DNS = append(l.ev.DNS, ev)
+}
+
+// addWhois records a WHOIS or RDAP response received for a query sent at
+// sent, both as received and decoded.
+func (l *evidenceLog) addWhois(sent time.Time, server, query string, raw []byte, decoded string) {
+	if l == nil || len(raw) == 0 {
+		return
+	}
+	ev := WhoisEvidence{Time: sent.UTC(), Server: server, Query: query, Raw: raw}
+	if decoded != string(raw) {
+		ev.Response = decoded
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ev.Whois = append(l.ev.Whois, ev)
+}
+
+// evidence returns a copy of what has been collected, or nil for a nil log.
+func (l *evidenceLog) evidence() *Evidence {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ev := Evidence{
+		DNS:   append([]DNSEvidence(nil), l.ev.DNS...),
+		Whois: append([]WhoisEvidence(nil), l.ev.Whois...),
+	}
+	return &ev
+}
+
+// typeName returns the mnemonic of a record type, e.g. "MX".
+func typeName(qtype dnsmessage.Type) string {
+	if qtype == typeCAA {
+		return "CAA"
+	}
+	name := qtype.String()
+	if !strings.HasPrefix(name, "Type") {
+		return "TYPE" + name
+	}
+	return strings.TrimPrefix(name, "Type")
+}
+
+// formatRR renders a resource record in zone file presentation format.
+func formatRR(rr dnsmessage.Resource) string {
+	var data string
+	switch body := rr.Body.(type) {
+	case *dnsmessage.AResource:
+		data = net.IP(body.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		data = net.IP(body.AAAA[:]).String()
+	case *dnsmessage.NSResource:
+		data = body.NS.String()
+	case *dnsmessage.CNAMEResource:
+		data = body.CNAME.String()
+	case *dnsmessage.MXResource:
+		data = fmt.Sprintf("%d %s", body.Pref, body.MX)
+	case *dnsmessage.TXTResource:
+		quoted := make([]string, len(body.TXT))
+		for i, txt := range body.TXT {
+			quoted[i] = fmt.Sprintf("%q", txt)
+		}
+		data = strings.Join(quoted, " ")
+	case *dnsmessage.SOAResource:
+		data = fmt.Sprintf("%s %s %d %d %d %d %d", body.NS, body.MBox, body.Serial, body.Refresh, body.Retry, body.Expire, body.MinTTL)
+	case *dnsmessage.UnknownResource:
+		if caa, ok := parseCAA(body.Data); ok && rr.Header.Type == typeCAA {
+			data = fmt.Sprintf("%d %s %q", caa.Flag, caa.Tag, caa.Value)
+		} else {
+			// RFC 3597 generic format.
+			data = fmt.Sprintf(`\# %d %s`, len(body.Data), hex.EncodeToString(body.Data))
+		}
+	default:
+		data = rr.Body.GoString()
+	}
+	return fmt.Sprintf("%s\t%d\tIN\t%s\t%s", rr.Header.Name, rr.Header.TTL, typeName(rr.Header.Type), data)
+}
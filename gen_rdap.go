@@ -0,0 +1,60 @@
+//go:build ignore
+
+// gen_rdap downloads the IANA RDAP bootstrap file for DNS into
+// data/rdap_dns.json. Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	bootstrapURL = "https://data.iana.org/rdap/dns.json"
+	outputPath   = "data/rdap_dns.json"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "gen_rdap:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Get(bootstrapURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", bootstrapURL, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// Refuse to replace the embedded copy with something that does not
+	// parse as a bootstrap file.
+	var doc struct {
+		Publication string       `json:"publication"`
+		Services    [][][]string `json:"services"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&doc); err != nil {
+		return fmt.Errorf("%s: %w", bootstrapURL, err)
+	}
+	if len(doc.Services) == 0 {
+		return fmt.Errorf("%s: no services", bootstrapURL)
+	}
+	if err := os.WriteFile(outputPath, body, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s: %d services, published %s\n", outputPath, len(doc.Services), doc.Publication)
+	return nil
+}
@@ -0,0 +1,7 @@
+module github.com/r3dcl1ff/TLDBuster
+
+go 1.23.0
+
+require golang.org/x/net v0.40.0
+
+require golang.org/x/text v0.25.0
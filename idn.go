@@ -0,0 +1,212 @@
+package tldbuster
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// embeddedConfusables is an excerpt of the Unicode confusables data from
+// https://www.unicode.org/Public/security/latest/confusables.txt.
+//
+//go:embed data/confusables.txt
+var embeddedConfusables []byte
+
+// Confusables maps a character to the characters that look like it.
+type Confusables map[rune][]rune
+
+var (
+	defaultConfusables     Confusables
+	defaultConfusablesOnce sync.Once
+)
+
+// DefaultConfusables returns the confusables embedded in the binary.
+func DefaultConfusables() Confusables {
+	defaultConfusablesOnce.Do(func() {
+		// The embedded copy is known to be well formed.
+		defaultConfusables, _ = LoadConfusables(bytes.NewReader(embeddedConfusables))
+	})
+	return defaultConfusables
+}
+
+// LoadConfusablesFile parses a confusables file from disk.
+func LoadConfusablesFile(name string) (Confusables, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadConfusables(file)
+}
+
+// LoadConfusables parses the confusables.txt format of Unicode Technical
+// Standard #39: "source ; prototype ; type # comment", with code points in
+// hexadecimal. Only single-character sources and prototypes are kept, and
+// the mapping is inverted so each prototype lists its lookalikes.
+func LoadConfusables(r io.Reader) (Confusables, error) {
+	c := make(Confusables)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("confusables line %d: expected \"source ; prototype\"", n)
+		}
+		source, prototype := strings.Fields(fields[0]), strings.Fields(fields[1])
+		if len(source) != 1 || len(prototype) != 1 {
+			continue
+		}
+		from, err := strconv.ParseUint(source[0], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("confusables line %d: %w", n, err)
+		}
+		to, err := strconv.ParseUint(prototype[0], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("confusables line %d: %w", n, err)
+		}
+		c[rune(to)] = append(c[rune(to)], unicode.ToLower(rune(from)))
+	}
+	return c, scanner.Err()
+}
+
+// homographScripts are the scripts tried for whole-script homographs.
+var homographScripts = []string{"Cyrillic", "Greek", "Armenian"}
+
+// homograph replaces characters with Unicode lookalikes: each single
+// substitution, plus whole-script variants where every letter has a
+// lookalike in the same script: "apple" -> "аpple", "аррӏе".
+func homograph(label string, data *permuteData) []string {
+	runes := []rune(label)
+	var out []string
+	for i, r := range runes {
+		for _, c := range data.confusables[r] {
+			variant := append([]rune(nil), runes...)
+			variant[i] = c
+			out = append(out, string(variant))
+		}
+	}
+	for _, script := range homographScripts {
+		table := unicode.Scripts[script]
+		variant := make([]rune, len(runes))
+		for i, r := range runes {
+			variant[i] = r
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			for _, c := range data.confusables[r] {
+				if unicode.Is(table, c) {
+					variant[i] = c
+					break
+				}
+			}
+			if variant[i] == r {
+				variant = nil
+				break
+			}
+		}
+		if variant != nil {
+			out = append(out, string(variant))
+		}
+	}
+	return out
+}
+
+// idnScripts lists the scripts each TLD accepts in IDN labels, following
+// the IDN tables the registries publish in the IANA Repository of IDN
+// Practices. Only the scripts covered by the confusables are listed; TLDs
+// missing from the table are treated as not offering IDNs.
+var idnScripts = map[string][]string{
+	"com":      {"Latin", "Cyrillic", "Greek", "Armenian"},
+	"net":      {"Latin", "Cyrillic", "Greek", "Armenian"},
+	"cc":       {"Latin", "Cyrillic", "Greek", "Armenian"},
+	"tv":       {"Latin", "Cyrillic", "Greek", "Armenian"},
+	"org":      {"Latin", "Cyrillic", "Greek"},
+	"info":     {"Latin", "Cyrillic", "Greek"},
+	"biz":      {"Latin", "Cyrillic", "Greek"},
+	"eu":       {"Latin", "Cyrillic", "Greek"},
+	"su":       {"Latin", "Cyrillic"},
+	"at":       {"Latin"},
+	"be":       {"Latin"},
+	"br":       {"Latin"},
+	"ch":       {"Latin"},
+	"de":       {"Latin"},
+	"dk":       {"Latin"},
+	"es":       {"Latin"},
+	"fi":       {"Latin"},
+	"fr":       {"Latin"},
+	"it":       {"Latin"},
+	"li":       {"Latin"},
+	"no":       {"Latin"},
+	"pl":       {"Latin"},
+	"pt":       {"Latin"},
+	"se":       {"Latin"},
+	"bg":       {"Cyrillic"},
+	"ua":       {"Cyrillic"},
+	"xn--p1ai": {"Cyrillic"},
+	"gr":       {"Greek"},
+	"am":       {"Armenian"},
+}
+
+// idnAllowed reports whether the TLD of suffix accepts the scripts of the
+// Unicode label. Registries reject most labels mixing scripts, but a single
+// foreign letter in a Latin label, such as the Cyrillic "а" in "exаmple",
+// is the most common homograph and is kept so the lookup can tell.
+func idnAllowed(suffix, label string) bool {
+	tld := suffix[strings.LastIndex(suffix, ".")+1:]
+	accepted, ok := idnScripts[tld]
+	if !ok {
+		return false
+	}
+	scripts := labelScripts(label)
+	for script := range scripts {
+		if !slices.Contains(accepted, script) {
+			return false
+		}
+	}
+	switch len(scripts) {
+	case 1:
+		return true
+	case 2:
+		foreign := 0
+		for _, r := range label {
+			if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+				foreign++
+			}
+		}
+		return scripts["Latin"] > 0 && foreign == 1
+	default:
+		return false
+	}
+}
+
+// labelScripts counts the letters of label per script. Letters outside
+// every script count under "".
+func labelScripts(label string) map[string]int {
+	scripts := make(map[string]int)
+	for _, r := range label {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		found := ""
+		for name, table := range unicode.Scripts {
+			if unicode.Is(table, r) {
+				found = name
+				break
+			}
+		}
+		scripts[found]++
+	}
+	return scripts
+}
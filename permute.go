@@ -0,0 +1,372 @@
+package tldbuster
+
+import (
+	"sort"
+	"strings"
+)
+
+// AlgorithmTLDSwap marks results that keep the original label and only
+// change the suffix.
+const AlgorithmTLDSwap = "tld-swap"
+
+// Permutation is a variant of the base label together with the algorithm
+// that produced it.
+type Permutation struct {
+	// Label is the variant in its ASCII (ACE) form.
+	Label string
+	// Unicode is the variant in Unicode for internationalised labels, and
+	// empty otherwise.
+	Unicode   string
+	Algorithm string
+	// MailHost marks doppelgangers of mail host names, e.g. "mailexample".
+	MailHost bool
+}
+
+// PermuteOptions supplies the data the permutation algorithms work from.
+type PermuteOptions struct {
+	// Keyboards are the layouts of the adjacent-key algorithms. Nil means
+	// DefaultKeyboards.
+	Keyboards []Keyboard
+	// Confusables lists the lookalikes of each character. Nil means
+	// DefaultConfusables.
+	Confusables Confusables
+	// Keywords are the words the combosquatting algorithm attaches to the
+	// label. Nil means DefaultKeywords.
+	Keywords []string
+	// Subdomains are the host names the doppelganger algorithm joins to the
+	// label. Nil means DefaultSubdomains.
+	Subdomains []string
+	// PhoneticThreshold is the minimum similarity, between 0 and 1, of the
+	// phonetic variants to the label. Zero means DefaultPhoneticThreshold.
+	PhoneticThreshold float64
+}
+
+// Keyboard is a keyboard layout used for adjacent-key typos.
+type Keyboard string
+
+// Supported keyboard layouts.
+const (
+	KeyboardQWERTY Keyboard = "qwerty"
+	KeyboardQWERTZ Keyboard = "qwertz"
+	KeyboardAZERTY Keyboard = "azerty"
+)
+
+// keyboardRows lists the LDH keys of each layout, top row first. Each row
+// sits slightly right of the one above it.
+var keyboardRows = map[Keyboard][]string{
+	KeyboardQWERTY: {"1234567890-", "qwertyuiop", "asdfghjkl", "zxcvbnm"},
+	KeyboardQWERTZ: {"1234567890", "qwertzuiop", "asdfghjkl", "yxcvbnm"},
+	KeyboardAZERTY: {"1234567890", "azertyuiop", "qsdfghjklm", "wxcvbn"},
+}
+
+// DefaultKeyboards are the layouts used when none are configured.
+var DefaultKeyboards = []Keyboard{KeyboardQWERTY, KeyboardQWERTZ, KeyboardAZERTY}
+
+// DefaultKeywords are the words phishing domains commonly add to a brand:
+// authentication, support, payment and region terms.
+var DefaultKeywords = []string{
+	"login", "signin", "sso", "auth", "account", "secure", "verify", "password", "my", "id",
+	"support", "help", "helpdesk", "service", "customer", "contact",
+	"pay", "payment", "billing", "invoice", "wallet", "checkout",
+	"us", "uk", "eu", "de", "fr", "asia", "global", "online",
+}
+
+// permuteData is the prepared form of PermuteOptions shared by all
+// algorithms of a run.
+type permuteData struct {
+	adjacent    map[byte]string
+	confusables Confusables
+	keywords    []string
+	subdomains  []string
+	threshold   float64
+}
+
+// permuter generates variants of a label, possibly in Unicode.
+type permuter func(label string, data *permuteData) []string
+
+// permuters holds the label permutation algorithms by name.
+var permuters = map[string]permuter{
+	"omission":       omission,
+	"repetition":     repetition,
+	"transposition":  transposition,
+	"replacement":    replacement,
+	"insertion":      insertion,
+	"vowel-swap":     vowelSwap,
+	"hyphenation":    hyphenation,
+	"plural":         plural,
+	"homograph":      homograph,
+	"bitsquatting":   bitsquatting,
+	"combosquatting": combosquatting,
+	"doppelganger":   doppelganger,
+	"phonetic":       phonetic,
+}
+
+// Algorithms returns the names of the available permutation algorithms.
+func Algorithms() []string {
+	names := make([]string, 0, len(permuters))
+	for name := range permuters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Permute runs the named algorithms on label and returns the distinct,
+// valid labels they produce, each tagged with the first algorithm that
+// generated it. The label itself is never returned; unknown algorithm
+// names are ignored. Punycode labels are not permuted, as typos of their
+// ASCII form do not correspond to anything a user would type.
+func Permute(label string, algorithms []string, opts PermuteOptions) []Permutation {
+	label = strings.ToLower(label)
+	if strings.HasPrefix(label, acePrefix) {
+		return nil
+	}
+	if len(opts.Keyboards) == 0 {
+		opts.Keyboards = DefaultKeyboards
+	}
+	if opts.Confusables == nil {
+		opts.Confusables = DefaultConfusables()
+	}
+	if opts.Keywords == nil {
+		opts.Keywords = DefaultKeywords
+	}
+	if opts.Subdomains == nil {
+		opts.Subdomains = DefaultSubdomains
+	}
+	if opts.PhoneticThreshold == 0 {
+		opts.PhoneticThreshold = DefaultPhoneticThreshold
+	}
+	data := &permuteData{
+		adjacent:    adjacentKeys(opts.Keyboards),
+		confusables: opts.Confusables,
+		keywords:    opts.Keywords,
+		subdomains:  opts.Subdomains,
+		threshold:   opts.PhoneticThreshold,
+	}
+	seen := map[string]bool{label: true}
+	var perms []Permutation
+	for _, name := range algorithms {
+		p, ok := permuters[name]
+		if !ok {
+			continue
+		}
+		for _, variant := range p(label, data) {
+			perm := Permutation{Label: variant, Algorithm: name}
+			if name == "doppelganger" {
+				perm.MailHost = mailDoppelganger(variant, label)
+			}
+			if !isASCII(variant) {
+				perm.Label, perm.Unicode = acePrefix+punyEncode(variant), variant
+			}
+			if seen[perm.Label] || !validLabel(perm.Label, perm.Unicode != "") {
+				continue
+			}
+			seen[perm.Label] = true
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+// validLabel reports whether s is a valid LDH label: letters, digits and
+// inner hyphens, at most 63 characters, without the "--" of reserved
+// labels such as "xn--" unless ace is set.
+func validLabel(s string, ace bool) bool {
+	if len(s) == 0 || len(s) > 63 || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	if len(s) >= 4 && s[2:4] == "--" && !(ace && strings.HasPrefix(s, acePrefix)) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// adjacentKeys merges the neighbouring keys of every key across layouts.
+func adjacentKeys(keyboards []Keyboard) map[byte]string {
+	sets := make(map[byte]map[byte]bool)
+	for _, kb := range keyboards {
+		rows := keyboardRows[kb]
+		for r, row := range rows {
+			for i := 0; i < len(row); i++ {
+				var near []byte
+				if i > 0 {
+					near = append(near, row[i-1])
+				}
+				if i+1 < len(row) {
+					near = append(near, row[i+1])
+				}
+				// Staggered rows: above are keys i and i+1, below i-1 and i.
+				if r > 0 {
+					near = append(near, keyAt(rows[r-1], i), keyAt(rows[r-1], i+1))
+				}
+				if r+1 < len(rows) {
+					near = append(near, keyAt(rows[r+1], i-1), keyAt(rows[r+1], i))
+				}
+				if sets[row[i]] == nil {
+					sets[row[i]] = make(map[byte]bool)
+				}
+				for _, c := range near {
+					if c != 0 {
+						sets[row[i]][c] = true
+					}
+				}
+			}
+		}
+	}
+	adjacent := make(map[byte]string, len(sets))
+	for key, set := range sets {
+		near := make([]byte, 0, len(set))
+		for c := range set {
+			near = append(near, c)
+		}
+		sort.Slice(near, func(i, j int) bool { return near[i] < near[j] })
+		adjacent[key] = string(near)
+	}
+	return adjacent
+}
+
+// keyAt returns the key at index i of a row, or 0 outside the row.
+func keyAt(row string, i int) byte {
+	if i < 0 || i >= len(row) {
+		return 0
+	}
+	return row[i]
+}
+
+// omission drops one character: "example" -> "exmple".
+func omission(label string, _ *permuteData) []string {
+	var out []string
+	for i := range label {
+		out = append(out, label[:i]+label[i+1:])
+	}
+	return out
+}
+
+// repetition doubles one character: "example" -> "exxample".
+func repetition(label string, _ *permuteData) []string {
+	var out []string
+	for i := range label {
+		out = append(out, label[:i+1]+label[i:])
+	}
+	return out
+}
+
+// transposition swaps two neighbouring characters: "example" -> "exmaple".
+func transposition(label string, _ *permuteData) []string {
+	var out []string
+	for i := 0; i+1 < len(label); i++ {
+		if label[i] != label[i+1] {
+			out = append(out, label[:i]+string(label[i+1])+string(label[i])+label[i+2:])
+		}
+	}
+	return out
+}
+
+// replacement replaces a character with an adjacent key: "example" -> "wxample".
+func replacement(label string, data *permuteData) []string {
+	var out []string
+	for i := range label {
+		for _, c := range []byte(data.adjacent[label[i]]) {
+			out = append(out, label[:i]+string(c)+label[i+1:])
+		}
+	}
+	return out
+}
+
+// insertion inserts an adjacent key next to a character: "example" -> "exsample".
+func insertion(label string, data *permuteData) []string {
+	var out []string
+	for i := range label {
+		for _, c := range []byte(data.adjacent[label[i]]) {
+			out = append(out,
+				label[:i]+string(c)+label[i:],
+				label[:i+1]+string(c)+label[i+1:])
+		}
+	}
+	return out
+}
+
+// vowelSwap replaces a vowel with another one: "example" -> "exampli".
+func vowelSwap(label string, _ *permuteData) []string {
+	const vowels = "aeiou"
+	var out []string
+	for i := range label {
+		if !strings.ContainsRune(vowels, rune(label[i])) {
+			continue
+		}
+		for _, v := range []byte(vowels) {
+			if v != label[i] {
+				out = append(out, label[:i]+string(v)+label[i+1:])
+			}
+		}
+	}
+	return out
+}
+
+// hyphenation inserts a hyphen between two characters: "example" -> "ex-ample".
+func hyphenation(label string, _ *permuteData) []string {
+	var out []string
+	for i := 1; i < len(label); i++ {
+		out = append(out, label[:i]+"-"+label[i:])
+	}
+	return out
+}
+
+// plural turns a singular label into its English plural and a plural one
+// into its singular: "example" -> "examples", "boxes" -> "box".
+func plural(label string, _ *permuteData) []string {
+	sibilant := func(s string) bool {
+		for _, end := range []string{"s", "x", "z", "ch", "sh"} {
+			if strings.HasSuffix(s, end) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case strings.HasSuffix(label, "ies") && len(label) > 3:
+		return []string{strings.TrimSuffix(label, "ies") + "y"}
+	case strings.HasSuffix(label, "es") && sibilant(strings.TrimSuffix(label, "es")):
+		return []string{strings.TrimSuffix(label, "es"), strings.TrimSuffix(label, "s")}
+	case strings.HasSuffix(label, "s") && !strings.HasSuffix(label, "ss"):
+		return []string{strings.TrimSuffix(label, "s")}
+	case sibilant(label):
+		return []string{label + "es"}
+	case len(label) > 1 && strings.HasSuffix(label, "y") && !strings.ContainsRune("aeiou", rune(label[len(label)-2])):
+		return []string{label[:len(label)-1] + "ies", label + "s"}
+	default:
+		return []string{label + "s"}
+	}
+}
+
+// bitsquatting flips each bit of each character, the corruption a memory
+// error causes: "example" -> "dxample". Flips that leave the LDH set, or
+// only change the case, are dropped as invalid labels; the high bit is
+// never set in LDH characters and is not flipped.
+func bitsquatting(label string, _ *permuteData) []string {
+	var out []string
+	for i := 0; i < len(label); i++ {
+		for bit := 0; bit < 7; bit++ {
+			c := label[i] ^ 1<<bit
+			out = append(out, label[:i]+string(c)+label[i+1:])
+		}
+	}
+	return out
+}
+
+// combosquatting attaches each keyword before and after the label, with
+// and without a hyphen: "example" -> "example-login", "secureexample".
+func combosquatting(label string, data *permuteData) []string {
+	var out []string
+	for _, word := range data.keywords {
+		word = strings.ToLower(word)
+		out = append(out, label+"-"+word, label+word, word+"-"+label, word+label)
+	}
+	return out
+}
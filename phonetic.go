@@ -0,0 +1,185 @@
+package tldbuster
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultPhoneticThreshold is the minimum similarity to the label of the
+// variants kept by the phonetic algorithm.
+const DefaultPhoneticThreshold = 0.65
+
+// graphemeGroups lists spellings that sound alike in English. Any member
+// of a group may replace any other.
+var graphemeGroups = [][]string{
+	{"f", "ph"},
+	{"k", "c", "ck"},
+	{"s", "z", "c"},
+	{"x", "ks", "cks", "cs", "gs", "ggs"},
+	{"kw", "qu"},
+	{"j", "g", "dg"},
+	{"v", "f"},
+	{"ai", "ay", "ei", "ey"},
+	{"ee", "ea", "ie", "ei"},
+	{"i", "y"},
+	{"oo", "ou", "u", "ew"},
+	{"oa", "ow", "oe"},
+	{"le", "el", "al"},
+	{"er", "ur", "ir", "re"},
+	{"or", "our"},
+}
+
+// phonetic replaces graphemes with sound-alike spellings, up to two at a
+// time: "example" -> "exampel", "eggsample". Variants that do not share
+// the phonetic key of the label, or whose similarity to it is below the
+// threshold, are dropped; the rest are returned most similar first.
+func phonetic(label string, data *permuteData) []string {
+	key := phoneticKey(label)
+	seen := map[string]bool{label: true}
+	var out []string
+	level := []string{label}
+	for depth := 0; depth < 2; depth++ {
+		var next []string
+		for _, s := range level {
+			for _, variant := range substituteGraphemes(s) {
+				if seen[variant] {
+					continue
+				}
+				seen[variant] = true
+				next = append(next, variant)
+				if phoneticKey(variant) == key && similarity(label, variant) >= data.threshold {
+					out = append(out, variant)
+				}
+			}
+		}
+		level = next
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return similarity(label, out[i]) > similarity(label, out[j])
+	})
+	return out
+}
+
+// substituteGraphemes returns s with each occurrence of each grapheme
+// replaced by the other members of its group, one at a time.
+func substituteGraphemes(s string) []string {
+	var out []string
+	for _, group := range graphemeGroups {
+		for _, from := range group {
+			for i := 0; i+len(from) <= len(s); i++ {
+				if s[i:i+len(from)] != from {
+					continue
+				}
+				for _, to := range group {
+					if to != from {
+						out = append(out, s[:i]+to+s[i+len(from):])
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// phoneticKey encodes how a label sounds with a simplified Metaphone:
+// consonants are reduced to one letter per sound, vowels are dropped after
+// the first letter and doubled letters count once, so "example",
+// "exampel" and "eggsample" all give "AKSMPL".
+func phoneticKey(s string) string {
+	vowel := func(i int) bool { return i < len(s) && strings.IndexByte("aeiouy", s[i]) >= 0 }
+	soft := func(i int) bool { return i < len(s) && strings.IndexByte("eiy", s[i]) >= 0 }
+	for _, silent := range []string{"kn", "gn", "pn", "wr"} {
+		if strings.HasPrefix(s, silent) {
+			s = s[1:]
+			break
+		}
+	}
+	var key strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		// Doubled letters sound once, except "cc" as in "accept".
+		if i > 0 && c == s[i-1] && c != 'c' {
+			continue
+		}
+		var code string
+		switch {
+		case vowel(i) && !(c == 'y' && vowel(i+1)):
+			if i == 0 {
+				code = "A"
+			}
+		case strings.HasPrefix(s[i:], "ph"):
+			code, i = "F", i+1
+		case strings.HasPrefix(s[i:], "sch"):
+			code, i = "SK", i+2
+		case strings.HasPrefix(s[i:], "sh") || strings.HasPrefix(s[i:], "ch"):
+			code, i = "X", i+1
+		case strings.HasPrefix(s[i:], "th"):
+			code, i = "0", i+1
+		case strings.HasPrefix(s[i:], "ck"):
+			code, i = "K", i+1
+		case strings.HasPrefix(s[i:], "dg") && soft(i+2):
+			code, i = "J", i+1
+		case strings.HasPrefix(s[i:], "gh"):
+			if i == 0 {
+				code = "K"
+			}
+			i++
+		case c == 'c' || c == 'g':
+			switch {
+			case c == 'c' && soft(i+1):
+				code = "S"
+			case c == 'g' && soft(i+1):
+				code = "J"
+			default:
+				code = "K"
+			}
+		case c == 'q':
+			code = "K"
+		case c == 'x':
+			code = "KS"
+			if i == 0 {
+				code = "S"
+			}
+		case c == 'z':
+			code = "S"
+		case c == 'v':
+			code = "F"
+		case c == 'h' || c == 'w' || c == 'y':
+			// Only sounded before a vowel.
+			if vowel(i + 1) {
+				code = strings.ToUpper(string(c))
+			}
+		case c == 'b' && i == len(s)-1 && s[i-1] == 'm':
+			// Silent final b, as in "thumb".
+		case c >= 'a' && c <= 'z':
+			code = strings.ToUpper(string(c))
+		}
+		key.WriteString(code)
+	}
+	return key.String()
+}
+
+// similarity returns 1 minus the Levenshtein distance of a and b relative
+// to the longer one: 1 for equal strings, 0 for entirely different ones.
+func similarity(a, b string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(b)])/float64(max(len(a), len(b)))
+}
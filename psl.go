@@ -0,0 +1,211 @@
+package tldbuster
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// embeddedPSL is a snapshot of https://publicsuffix.org/list/public_suffix_list.dat.
+//
+//go:embed data/public_suffix_list.dat
+var embeddedPSL []byte
+
+// Kinds of Public Suffix List rules. A name may carry several kinds at
+// once, e.g. both "kawasaki.jp" and "*.kawasaki.jp".
+const (
+	ruleNormal = 1 << iota
+	ruleWildcard
+	ruleException
+)
+
+// pslRule holds the rules registered for a single name.
+type pslRule struct {
+	kinds int
+	icann bool
+}
+
+// SuffixList is a parsed Public Suffix List.
+type SuffixList struct {
+	// ICANNOnly ignores rules from the PRIVATE DOMAINS section.
+	ICANNOnly bool
+
+	rules map[string]pslRule
+}
+
+var (
+	defaultPSL     *SuffixList
+	defaultPSLOnce sync.Once
+)
+
+// DefaultSuffixList returns the Public Suffix List embedded in the binary.
+func DefaultSuffixList() *SuffixList {
+	defaultPSLOnce.Do(func() {
+		// The embedded copy is known to be well formed.
+		defaultPSL, _ = LoadSuffixList(bytes.NewReader(embeddedPSL))
+	})
+	return defaultPSL
+}
+
+// LoadSuffixListFile parses a Public Suffix List file from disk.
+func LoadSuffixListFile(path string) (*SuffixList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadSuffixList(file)
+}
+
+// LoadSuffixList parses a Public Suffix List in the publicsuffix.org format.
+func LoadSuffixList(r io.Reader) (*SuffixList, error) {
+	l := &SuffixList{rules: make(map[string]pslRule)}
+	icann := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.Contains(line, "===BEGIN ICANN DOMAINS==="):
+			icann = true
+			continue
+		case strings.Contains(line, "===END ICANN DOMAINS==="):
+			icann = false
+			continue
+		case line == "" || strings.HasPrefix(line, "//"):
+			continue
+		}
+		// Rules end at the first whitespace.
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			line = line[:i]
+		}
+
+		kind := ruleNormal
+		switch {
+		case strings.HasPrefix(line, "!"):
+			kind = ruleException
+			line = line[1:]
+		case strings.HasPrefix(line, "*."):
+			kind = ruleWildcard
+			line = line[2:]
+		}
+		name := toASCII(strings.ToLower(line))
+		rule := l.rules[name]
+		rule.kinds |= kind
+		rule.icann = icann
+		l.rules[name] = rule
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// PublicSuffix returns the public suffix of domain and whether it comes
+// from the ICANN section. Domains matching no rule fall back to their last
+// label, as the PSL algorithm prescribes.
+func (l *SuffixList) PublicSuffix(domain string) (string, bool) {
+	domain = toASCII(strings.ToLower(strings.TrimSuffix(domain, ".")))
+	labels := strings.Split(domain, ".")
+
+	suffixLen, icann := 0, false
+	// Walk from the longest candidate name to the shortest.
+	for i := 0; i < len(labels); i++ {
+		rule, ok := l.rules[strings.Join(labels[i:], ".")]
+		if !ok || (l.ICANNOnly && !rule.icann) {
+			continue
+		}
+		// An exception always prevails and drops its leftmost label.
+		if rule.kinds&ruleException != 0 {
+			return strings.Join(labels[i+1:], "."), rule.icann
+		}
+		n := len(labels) - i
+		if rule.kinds&ruleWildcard != 0 && i > 0 && n+1 > suffixLen {
+			suffixLen, icann = n+1, rule.icann
+		}
+		if rule.kinds&ruleNormal != 0 && n > suffixLen {
+			suffixLen, icann = n, rule.icann
+		}
+	}
+	if suffixLen == 0 {
+		// The implicit "*" rule.
+		suffixLen = 1
+	}
+	return strings.Join(labels[len(labels)-suffixLen:], "."), icann
+}
+
+// RegistrableDomain returns the public suffix of domain plus one label, or
+// an empty string when domain is itself a public suffix.
+func (l *SuffixList) RegistrableDomain(domain string) string {
+	base, suffix := l.Split(domain)
+	if base == "" {
+		return ""
+	}
+	return base + "." + suffix
+}
+
+// Split returns the label directly left of the public suffix together with
+// the suffix, so "www.example.co.uk" becomes ("example", "co.uk").
+func (l *SuffixList) Split(domain string) (string, string) {
+	domain = toASCII(strings.ToLower(strings.TrimSuffix(domain, ".")))
+	suffix, _ := l.PublicSuffix(domain)
+	if len(domain) <= len(suffix) {
+		return "", ""
+	}
+	rest := strings.TrimSuffix(domain[:len(domain)-len(suffix)], ".")
+	if i := strings.LastIndex(rest, "."); i >= 0 {
+		rest = rest[i+1:]
+	}
+	return rest, suffix
+}
+
+// restrictedSecondLevel are second-level labels reserved for governments,
+// the military, schools and similar bodies. Nobody else can register under
+// them, so they are not worth a lookup.
+var restrictedSecondLevel = map[string]bool{
+	"ac": true, "edu": true, "go": true, "gob": true, "gouv": true, "gov": true,
+	"govt": true, "gv": true, "int": true, "mil": true, "mod": true, "nic": true,
+	"police": true, "sch": true, "school": true,
+}
+
+// SecondLevel returns the ICANN second-level public suffixes of ccTLDs,
+// keyed by ccTLD. Labels of restricted spaces such as "gov", "edu" and
+// "mil" are left out, and of the others only those used under at least
+// minCount different ccTLDs are kept: the open registration spaces such as
+// "co", "com" and "org", but not regional ones.
+func (l *SuffixList) SecondLevel(minCount int) map[string][]string {
+	byLabel := make(map[string][]string)
+	for name, rule := range l.rules {
+		if !rule.icann || rule.kinds&ruleNormal == 0 {
+			continue
+		}
+		label, tld, ok := strings.Cut(name, ".")
+		if !ok || !isCCTLD(tld) || restrictedSecondLevel[label] {
+			continue
+		}
+		byLabel[label] = append(byLabel[label], tld)
+	}
+
+	out := make(map[string][]string)
+	for label, tlds := range byLabel {
+		if len(tlds) < minCount {
+			continue
+		}
+		for _, tld := range tlds {
+			out[tld] = append(out[tld], label+"."+tld)
+		}
+	}
+	for _, suffixes := range out {
+		sort.Strings(suffixes)
+	}
+	return out
+}
+
+// isCCTLD reports whether tld is a two-letter country-code TLD.
+func isCCTLD(tld string) bool {
+	return len(tld) == 2 && tld[0] >= 'a' && tld[0] <= 'z' && tld[1] >= 'a' && tld[1] <= 'z'
+}
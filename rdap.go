@@ -0,0 +1,404 @@
+package tldbuster
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:generate go run gen_rdap.go
+
+// embeddedRDAPServices is the snapshot of https://data.iana.org/rdap/dns.json
+// that go generate writes. LoadRDAPBootstrapFile loads a newer copy at run
+// time.
+//
+//go:embed data/rdap_dns.json
+var embeddedRDAPServices []byte
+
+// rdapTimeout bounds a single RDAP request.
+const rdapTimeout = 15 * time.Second
+
+// maxRDAPResponse caps how much of an RDAP response is read.
+const maxRDAPResponse = 4 << 20
+
+var (
+	// ErrNoRDAPServer is returned when the bootstrap lists no server for a TLD.
+	ErrNoRDAPServer = errors.New("no RDAP server for TLD")
+	// ErrRDAPNotFound is returned when the RDAP server does not know the domain.
+	ErrRDAPNotFound = errors.New("domain not found in RDAP")
+)
+
+// RDAPBootstrap maps TLDs to RDAP base URLs (RFC 9224).
+type RDAPBootstrap struct {
+	services map[string][]string
+}
+
+var (
+	defaultRDAPBootstrap     *RDAPBootstrap
+	defaultRDAPBootstrapOnce sync.Once
+)
+
+// DefaultRDAPBootstrap returns the bootstrap data embedded in the binary.
+func DefaultRDAPBootstrap() *RDAPBootstrap {
+	defaultRDAPBootstrapOnce.Do(func() {
+		// The embedded copy is known to be well formed.
+		defaultRDAPBootstrap, _ = LoadRDAPBootstrap(bytes.NewReader(embeddedRDAPServices))
+	})
+	return defaultRDAPBootstrap
+}
+
+// LoadRDAPBootstrapFile parses a bootstrap file such as a fresh copy of
+// https://data.iana.org/rdap/dns.json.
+func LoadRDAPBootstrapFile(path string) (*RDAPBootstrap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadRDAPBootstrap(file)
+}
+
+// LoadRDAPBootstrap parses bootstrap data in the RFC 9224 format.
+func LoadRDAPBootstrap(r io.Reader) (*RDAPBootstrap, error) {
+	var doc struct {
+		Services [][][]string `json:"services"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	b := &RDAPBootstrap{services: make(map[string][]string)}
+	for _, service := range doc.Services {
+		if len(service) != 2 {
+			return nil, fmt.Errorf("malformed RDAP bootstrap service %v", service)
+		}
+		for _, tld := range service[0] {
+			b.services[strings.ToLower(tld)] = service[1]
+		}
+	}
+	return b, nil
+}
+
+// BaseURLs returns the RDAP base URLs of tld, HTTPS first.
+func (b *RDAPBootstrap) BaseURLs(tld string) []string {
+	urls := append([]string(nil), b.services[strings.ToLower(tld)]...)
+	for i, u := range urls {
+		if strings.HasPrefix(u, "https://") && i > 0 {
+			urls[0], urls[i] = urls[i], urls[0]
+			break
+		}
+	}
+	return urls
+}
+
+// RDAPClient is a WhoisClient using the Registration Data Access Protocol.
+type RDAPClient struct {
+	// HTTPClient performs the requests. Nil means a client with a timeout.
+	HTTPClient *http.Client
+	// Bootstrap maps TLDs to RDAP servers. Nil means the embedded copy.
+	Bootstrap *RDAPBootstrap
+}
+
+// NewRDAPClient returns an RDAP client using the embedded bootstrap data.
+func NewRDAPClient() *RDAPClient {
+	return &RDAPClient{}
+}
+
+// Lookup fetches the RDAP domain object of domain from the registry and,
+// for thin registries, from the registrar it links to.
+func (c *RDAPClient) Lookup(ctx context.Context, domain string) (*WhoisRecord, error) {
+	bootstrap := c.Bootstrap
+	if bootstrap == nil {
+		bootstrap = DefaultRDAPBootstrap()
+	}
+	tld := getTLD(domain)
+	bases := bootstrap.BaseURLs(tld)
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("%s: %w", tld, ErrNoRDAPServer)
+	}
+
+	var lastErr error
+	for _, base := range bases {
+		if !strings.HasSuffix(base, "/") {
+			base += "/"
+		}
+		obj, err := c.fetch(ctx, base+"domain/"+domain)
+		if err != nil {
+			lastErr = err
+			if errors.Is(err, ErrRDAPNotFound) {
+				break
+			}
+			continue
+		}
+		rec := obj.record()
+		rec.Server = base
+		// Thin registries link to the registrar's own RDAP service.
+		if related := obj.relatedLink(); related != "" && rec.Registrant == "" {
+			if robj, err := c.fetch(ctx, related); err == nil {
+				rec.merge(robj.record())
+				rec.Referral = related
+			}
+		}
+		return rec, nil
+	}
+	return nil, lastErr
+}
+
+// fetch retrieves and decodes a single RDAP domain object.
+func (c *RDAPClient) fetch(ctx context.Context, url string) (*rdapDomain, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: rdapTimeout}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+	sent := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrRDAPNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("RDAP %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRDAPResponse))
+	if err != nil {
+		return nil, fmt.Errorf("RDAP %s: %w", url, err)
+	}
+	evidenceFrom(ctx).addWhois(sent, url, "", body, string(body))
+	var obj rdapDomain
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("RDAP %s: %w", url, err)
+	}
+	return &obj, nil
+}
+
+// rdapDomain is the subset of an RDAP domain object (RFC 9083) we use.
+type rdapDomain struct {
+	Status []string `json:"status"`
+	Events []struct {
+		Action string    `json:"eventAction"`
+		Date   time.Time `json:"eventDate"`
+	} `json:"events"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+	Entities []rdapEntity `json:"entities"`
+	Links    []rdapLink   `json:"links"`
+}
+
+// rdapEntity is a contact attached to an RDAP object.
+type rdapEntity struct {
+	Roles      []string        `json:"roles"`
+	VCardArray json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity    `json:"entities"`
+}
+
+// rdapLink is a link of an RDAP object.
+type rdapLink struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+	Type string `json:"type"`
+}
+
+// record converts the domain object to a WhoisRecord.
+func (d *rdapDomain) record() *WhoisRecord {
+	rec := &WhoisRecord{Source: "rdap", Status: d.Status}
+	for _, ev := range d.Events {
+		rec.Events = append(rec.Events, Event{Action: ev.Action, Date: ev.Date})
+		date := ev.Date.UTC()
+		switch ev.Action {
+		case "registration":
+			rec.Created = &date
+		case "last changed":
+			rec.Updated = &date
+		case "expiration":
+			rec.Expires = &date
+		}
+	}
+	for _, ns := range d.Nameservers {
+		rec.NameServers = append(rec.NameServers, strings.ToLower(ns.LDHName))
+	}
+	for _, ent := range d.Entities {
+		props := ent.vcard()
+		switch {
+		case ent.hasRole("registrar") && rec.Registrar == "":
+			rec.Registrar = ent.name()
+			// The abuse contact is nested inside the registrar entity.
+			for _, sub := range ent.Entities {
+				if sub.hasRole("abuse") {
+					subProps := sub.vcard()
+					rec.AbuseEmail = subProps["email"]
+					rec.AbusePhone = subProps["tel"]
+				}
+			}
+		case ent.hasRole("registrant") && rec.Registrant == "":
+			rec.Registrant = ent.name()
+			rec.RegistrantOrg = props["org"]
+			rec.RegistrantEmail = props["email"]
+			rec.RegistrantCountry = props["country"]
+		}
+	}
+	return rec
+}
+
+// relatedLink returns the registrar RDAP URL of a thin registry answer.
+func (d *rdapDomain) relatedLink() string {
+	for _, link := range d.Links {
+		if link.Rel == "related" && strings.Contains(link.Type, "rdap+json") {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// hasRole reports whether the entity carries role.
+func (e *rdapEntity) hasRole(role string) bool {
+	for _, r := range e.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// name returns the organisation or formatted name from the entity's jCard.
+func (e *rdapEntity) name() string {
+	props := e.vcard()
+	if org := props["org"]; org != "" {
+		return org
+	}
+	return props["fn"]
+}
+
+// vcard flattens the text properties of a jCard (RFC 7095) into a map.
+// Structured values keep their first non-empty component.
+func (e *rdapEntity) vcard() map[string]string {
+	props := make(map[string]string)
+	var card []json.RawMessage
+	if json.Unmarshal(e.VCardArray, &card) != nil || len(card) != 2 {
+		return props
+	}
+	var entries [][]json.RawMessage
+	if json.Unmarshal(card[1], &entries) != nil {
+		return props
+	}
+	for _, entry := range entries {
+		if len(entry) < 4 {
+			continue
+		}
+		var name string
+		if json.Unmarshal(entry[0], &name) != nil {
+			continue
+		}
+		name = strings.ToLower(name)
+		if name == "adr" {
+			if _, seen := props["country"]; !seen {
+				props["country"] = jcardCountry(entry[1], entry[3])
+			}
+			continue
+		}
+		if _, seen := props[name]; seen {
+			continue
+		}
+		props[name] = jcardText(entry[3])
+	}
+	return props
+}
+
+// jcardCountry returns the country of a jCard adr property, preferring the
+// "cc" parameter over the country-name component.
+func jcardCountry(params, value json.RawMessage) string {
+	var p map[string]interface{}
+	if json.Unmarshal(params, &p) == nil {
+		if cc, ok := p["cc"].(string); ok && cc != "" {
+			return cc
+		}
+	}
+	var parts []json.RawMessage
+	if json.Unmarshal(value, &parts) == nil && len(parts) == 7 {
+		return jcardText(parts[6])
+	}
+	return ""
+}
+
+// jcardText returns a jCard value as text.
+func jcardText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return strings.TrimSpace(text)
+	}
+	var parts []json.RawMessage
+	if json.Unmarshal(raw, &parts) == nil {
+		for _, part := range parts {
+			if t := jcardText(part); t != "" {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// merge fills the empty fields of r from other, which takes precedence for
+// registrant details.
+func (r *WhoisRecord) merge(other *WhoisRecord) {
+	if other.Registrant != "" {
+		r.Registrant = other.Registrant
+		r.RegistrantOrg = other.RegistrantOrg
+		r.RegistrantEmail = other.RegistrantEmail
+		r.RegistrantCountry = other.RegistrantCountry
+	}
+	if r.AbuseEmail == "" {
+		r.AbuseEmail, r.AbusePhone = other.AbuseEmail, other.AbusePhone
+	}
+	if r.Registrar == "" {
+		r.Registrar = other.Registrar
+	}
+	if len(r.Status) == 0 {
+		r.Status = other.Status
+	}
+	if len(r.NameServers) == 0 {
+		r.NameServers = other.NameServers
+	}
+	if len(r.Events) == 0 {
+		r.Events = other.Events
+	}
+}
+
+// FallbackWhois is a WhoisClient that asks Primary first and Fallback when
+// Primary fails or returns no registration details.
+type FallbackWhois struct {
+	Primary  WhoisClient
+	Fallback WhoisClient
+}
+
+// Lookup implements WhoisClient.
+func (f *FallbackWhois) Lookup(ctx context.Context, domain string) (*WhoisRecord, error) {
+	rec, err := f.Primary.Lookup(ctx, domain)
+	if !rec.empty() {
+		return rec, err
+	}
+	frec, ferr := f.Fallback.Lookup(ctx, domain)
+	if frec == nil {
+		// Keep the primary answer, however thin, when the fallback fails too.
+		if rec != nil {
+			return rec, err
+		}
+		return nil, errors.Join(err, ferr)
+	}
+	return frec, ferr
+}
@@ -0,0 +1,121 @@
+package tldbuster
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// typeCAA is the CAA record type (RFC 8659), unknown to dnsmessage.
+const typeCAA dnsmessage.Type = 257
+
+// DNSRecords holds the DNS records collected for a discovered domain.
+type DNSRecords struct {
+	NS    []string    `json:"ns,omitempty"`
+	MX    []MXRecord  `json:"mx,omitempty"`
+	TXT   []string    `json:"txt,omitempty"`
+	CNAME string      `json:"cname,omitempty"`
+	SOA   *SOARecord  `json:"soa,omitempty"`
+	CAA   []CAARecord `json:"caa,omitempty"`
+}
+
+// MXRecord is a mail exchanger.
+type MXRecord struct {
+	Preference uint16 `json:"preference"`
+	Host       string `json:"host"`
+}
+
+// SOARecord is the start of authority of a zone.
+type SOARecord struct {
+	NS      string `json:"ns"`
+	MBox    string `json:"mbox"`
+	Serial  uint32 `json:"serial"`
+	Refresh uint32 `json:"refresh"`
+	Retry   uint32 `json:"retry"`
+	Expire  uint32 `json:"expire"`
+	MinTTL  uint32 `json:"min_ttl"`
+}
+
+// CAARecord is a certification authority authorization entry.
+type CAARecord struct {
+	Flag  uint8  `json:"flag"`
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// collectRecords queries the NS, MX, TXT, CNAME, SOA and CAA records of domain.
+func (s *Scanner) collectRecords(ctx context.Context, domain string) *DNSRecords {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	recs := &DNSRecords{}
+	for _, qtype := range []dnsmessage.Type{
+		dnsmessage.TypeNS, dnsmessage.TypeMX, dnsmessage.TypeTXT,
+		dnsmessage.TypeCNAME, dnsmessage.TypeSOA, typeCAA,
+	} {
+		sent := time.Now()
+		resp, status := s.query(ctx, domain, qtype)
+		evidenceFrom(ctx).addDNS(sent, domain, qtype, "", status, resp)
+		if status != StatusResolved {
+			continue
+		}
+		for _, rr := range resp.Answers {
+			// Skip records of other names in a CNAME chain.
+			if !strings.EqualFold(rr.Header.Name.String(), dnsName(domain)) {
+				continue
+			}
+			recs.add(rr)
+		}
+	}
+	return recs
+}
+
+// add stores a single resource record in the matching field.
+func (r *DNSRecords) add(rr dnsmessage.Resource) {
+	switch body := rr.Body.(type) {
+	case *dnsmessage.NSResource:
+		r.NS = append(r.NS, hostName(body.NS))
+	case *dnsmessage.MXResource:
+		r.MX = append(r.MX, MXRecord{Preference: body.Pref, Host: hostName(body.MX)})
+	case *dnsmessage.TXTResource:
+		r.TXT = append(r.TXT, strings.Join(body.TXT, ""))
+	case *dnsmessage.CNAMEResource:
+		r.CNAME = hostName(body.CNAME)
+	case *dnsmessage.SOAResource:
+		r.SOA = &SOARecord{
+			NS:      hostName(body.NS),
+			MBox:    hostName(body.MBox),
+			Serial:  body.Serial,
+			Refresh: body.Refresh,
+			Retry:   body.Retry,
+			Expire:  body.Expire,
+			MinTTL:  body.MinTTL,
+		}
+	case *dnsmessage.UnknownResource:
+		if rr.Header.Type == typeCAA {
+			if caa, ok := parseCAA(body.Data); ok {
+				r.CAA = append(r.CAA, caa)
+			}
+		}
+	}
+}
+
+// parseCAA decodes the RDATA of a CAA record: flags, tag length, tag, value.
+func parseCAA(data []byte) (CAARecord, bool) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return CAARecord{}, false
+	}
+	tagLen := int(data[1])
+	return CAARecord{
+		Flag:  data[0],
+		Tag:   string(data[2 : 2+tagLen]),
+		Value: string(data[2+tagLen:]),
+	}, true
+}
+
+// hostName returns a DNS name in lowercase without the trailing dot.
+func hostName(name dnsmessage.Name) string {
+	return strings.TrimSuffix(strings.ToLower(name.String()), ".")
+}
@@ -0,0 +1,297 @@
+package tldbuster
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// LookupStatus is the outcome of a DNS lookup.
+type LookupStatus string
+
+const (
+	// StatusResolved means the name has records of the queried type.
+	StatusResolved LookupStatus = "resolved"
+	// StatusNoData means the name exists but has no records of the queried type.
+	StatusNoData LookupStatus = "nodata"
+	// StatusNXDomain means the name does not exist.
+	StatusNXDomain LookupStatus = "nxdomain"
+	// StatusServFail means the server failed to answer.
+	StatusServFail LookupStatus = "servfail"
+	// StatusRefused means the server refused the query.
+	StatusRefused LookupStatus = "refused"
+	// StatusTimeout means no answer arrived in time.
+	StatusTimeout LookupStatus = "timeout"
+	// StatusError covers any other failure.
+	StatusError LookupStatus = "error"
+)
+
+// Exists reports whether the status proves the name exists.
+func (s LookupStatus) Exists() bool {
+	return s == StatusResolved || s == StatusNoData
+}
+
+// Failed reports whether the lookup did not produce a definite answer.
+func (s LookupStatus) Failed() bool {
+	return !s.Exists() && s != StatusNXDomain && s != StatusNotRegistrable
+}
+
+// Response is a DNS answer together with its classification.
+type Response struct {
+	Status      LookupStatus
+	Answers     []dnsmessage.Resource
+	Authorities []dnsmessage.Resource
+	Additionals []dnsmessage.Resource
+}
+
+// Resolver answers DNS queries. Transport failures are reported as errors;
+// any response from a server, including NXDOMAIN, is not an error.
+type Resolver interface {
+	Query(ctx context.Context, name string, qtype dnsmessage.Type) (*Response, error)
+}
+
+// DNSResolver is a minimal recursive-client Resolver talking to the
+// configured name servers over UDP, falling back to TCP on truncation.
+type DNSResolver struct {
+	// Servers are tried in order, as host:port.
+	Servers []string
+	// Timeout bounds a single exchange with one server.
+	Timeout time.Duration
+}
+
+// dnsTimeout is the default per-exchange timeout.
+const dnsTimeout = 3 * time.Second
+
+// ednsBufferSize is the UDP payload size advertised through EDNS0.
+const ednsBufferSize = 1232
+
+// NewResolver returns a DNSResolver using the given servers. Servers
+// without a port use port 53. With no servers, the system configuration in
+// /etc/resolv.conf is used.
+func NewResolver(servers ...string) *DNSResolver {
+	if len(servers) == 0 {
+		servers = systemNameServers()
+	}
+	r := &DNSResolver{Timeout: dnsTimeout}
+	for _, server := range servers {
+		r.Servers = append(r.Servers, withPort(server, "53"))
+	}
+	return r
+}
+
+// systemNameServers reads the name servers from /etc/resolv.conf.
+func systemNameServers() []string {
+	servers := []string{}
+	file, err := os.Open("/etc/resolv.conf")
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "nameserver" {
+				servers = append(servers, fields[1])
+			}
+		}
+	}
+	if len(servers) == 0 {
+		servers = append(servers, "127.0.0.1")
+	}
+	return servers
+}
+
+// withPort appends port to host unless it already carries one.
+func withPort(host, port string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
+
+// Query sends a recursive query for name to the configured servers.
+func (r *DNSResolver) Query(ctx context.Context, name string, qtype dnsmessage.Type) (*Response, error) {
+	var lastErr error
+	for _, server := range r.Servers {
+		resp, err := r.Exchange(ctx, server, name, qtype, true)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no name servers configured")
+	}
+	return nil, lastErr
+}
+
+// Exchange sends a single query to server. recursive sets the RD bit.
+func (r *DNSResolver) Exchange(ctx context.Context, server, name string, qtype dnsmessage.Type, recursive bool) (*Response, error) {
+	qname, err := dnsmessage.NewName(dnsName(name))
+	if err != nil {
+		return nil, err
+	}
+	id := uint16(rand.Uint32())
+	query, err := buildQuery(id, qname, qtype, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = dnsTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	reply, err := exchangeUDP(ctx, server, query, id)
+	if err != nil {
+		return nil, err
+	}
+	var msg dnsmessage.Message
+	if err := msg.Unpack(reply); err != nil {
+		return nil, err
+	}
+	if msg.Truncated {
+		if reply, err = exchangeTCP(ctx, server, query); err != nil {
+			return nil, err
+		}
+		if err := msg.Unpack(reply); err != nil {
+			return nil, err
+		}
+	}
+	return newResponse(&msg, qtype), nil
+}
+
+// dnsName returns name in fully qualified form.
+func dnsName(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// buildQuery packs a query message with an EDNS0 OPT record.
+func buildQuery(id uint16, name dnsmessage.Name, qtype dnsmessage.Type, recursive bool) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: recursive})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	if err := b.StartAdditionals(); err != nil {
+		return nil, err
+	}
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(ednsBufferSize, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, err
+	}
+	if err := b.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// exchangeUDP sends query over UDP and waits for the reply with a matching ID.
+func exchangeUDP(ctx context.Context, server string, query []byte, id uint16) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, ednsBufferSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Ignore stray datagrams that do not answer our query.
+		if n >= 2 && binary.BigEndian.Uint16(buf) == id {
+			return buf[:n], nil
+		}
+	}
+}
+
+// exchangeTCP sends query over TCP using the two-byte length framing.
+func exchangeTCP(ctx context.Context, server string, query []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	framed := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(framed, uint16(len(query)))
+	copy(framed[2:], query)
+	if _, err := conn.Write(framed); err != nil {
+		return nil, err
+	}
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// newResponse classifies a DNS message for the given query type.
+func newResponse(msg *dnsmessage.Message, qtype dnsmessage.Type) *Response {
+	resp := &Response{
+		Answers:     msg.Answers,
+		Authorities: msg.Authorities,
+		Additionals: msg.Additionals,
+	}
+	switch msg.RCode {
+	case dnsmessage.RCodeSuccess:
+		resp.Status = StatusNoData
+		for _, rr := range msg.Answers {
+			if rr.Header.Type == qtype {
+				resp.Status = StatusResolved
+				break
+			}
+		}
+	case dnsmessage.RCodeNameError:
+		resp.Status = StatusNXDomain
+	case dnsmessage.RCodeServerFailure:
+		resp.Status = StatusServFail
+	case dnsmessage.RCodeRefused:
+		resp.Status = StatusRefused
+	default:
+		resp.Status = StatusError
+	}
+	return resp
+}
+
+// errorStatus classifies a transport error returned by a Resolver.
+func errorStatus(err error) LookupStatus {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return StatusTimeout
+	}
+	return StatusError
+}
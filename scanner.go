@@ -0,0 +1,597 @@
+// Package tldbuster discovers registered variants of a domain across
+// different Top-Level Domains.
+package tldbuster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultConcurrency is the number of workers used when none is configured.
+	DefaultConcurrency = 20
+	// DefaultSecondLevelMin is the usual threshold for WithSecondLevel.
+	DefaultSecondLevelMin = 10
+)
+
+var (
+	// ErrNoBaseName is returned when the input is itself a public suffix.
+	ErrNoBaseName = errors.New("could not extract base name")
+	// ErrNotFound is returned when the original domain does not resolve.
+	ErrNotFound = errors.New("domain does not exist")
+)
+
+// Result holds information about a discovered domain.
+type Result struct {
+	Domain string `json:"domain"`
+	// UnicodeDomain is the Unicode form of an internationalised Domain,
+	// which is always given in ASCII (ACE) form.
+	UnicodeDomain   string            `json:"unicode_domain,omitempty"`
+	Status          LookupStatus      `json:"status"`
+	IPs             []string          `json:"ips"`
+	WhoisServer     string            `json:"whois_server,omitempty"`
+	WhoisReferral   string            `json:"whois_referral,omitempty"`
+	WhoisError      string            `json:"whois_error,omitempty"`
+	WhoisIncomplete bool              `json:"whois_incomplete,omitempty"`
+	Registrant      string            `json:"registrant"`
+	Privacy         RegistrantPrivacy `json:"privacy,omitempty"`
+	PrivacyService  string            `json:"privacy_service,omitempty"`
+	Server          string            `json:"server"`
+	Whois           *WhoisRecord      `json:"whois,omitempty"`
+	Delegation      DelegationStatus  `json:"delegation,omitempty"`
+	DelegationNS    []string          `json:"delegation_ns,omitempty"`
+	Records         *DNSRecords       `json:"records,omitempty"`
+	Wildcard        bool              `json:"wildcard,omitempty"`
+	Reason          string            `json:"reason,omitempty"`
+	// Algorithm names the permutation that produced the domain, or
+	// AlgorithmTLDSwap when only the suffix differs from the original.
+	Algorithm string `json:"algorithm,omitempty"`
+	// MailDoppelganger marks doppelgangers of a mail host, such as
+	// mailexample.com, which receive email mistyped for mail.example.com.
+	MailDoppelganger bool      `json:"mail_doppelganger,omitempty"`
+	Evidence         *Evidence `json:"evidence,omitempty"`
+	// Ownership attributes the variant relative to the original domain;
+	// OwnershipSignals lists what it has in common with the original.
+	Ownership        Ownership `json:"ownership,omitempty"`
+	OwnershipSignals []string  `json:"ownership_signals,omitempty"`
+}
+
+// Task defines a candidate domain lookup task.
+type Task struct {
+	baseName     string
+	unicodeName  string
+	mailHost     bool
+	candidateTLD string
+	algorithm    string
+	baseline     *Fingerprint
+	// unregistrable marks typo TLDs outside the root zone, which are
+	// reported without lookups.
+	unregistrable bool
+}
+
+// Domain returns the candidate domain name of the task.
+func (t Task) Domain() string {
+	return t.baseName + "." + t.candidateTLD
+}
+
+// Scanner enumerates TLD variants of a domain.
+type Scanner struct {
+	tlds        []string
+	suffixes    *SuffixList
+	secondLevel int
+	candidates  []string
+	wildcard    WildcardPolicy
+	allowlist   *Allowlist
+	allowPolicy AllowlistPolicy
+	suppressed  atomic.Int64
+	wildcards   suffixCache[map[string]struct{}]
+	resolver    Resolver
+	retries     int
+	delegation  bool
+	exchanger   Exchanger
+	parents     suffixCache[[]string]
+	records     bool
+	evidence    bool
+	attribution bool
+	algorithms  []string
+	permOpts    PermuteOptions
+	permTLDs    []string
+	idnPolicy   bool
+	typoMode    TLDTypoMode
+	asns        suffixCache[[]string]
+	whois       WhoisClient
+	concurrency int
+	onResult    func(Result)
+	debugLog    *log.Logger
+	verboseLog  *log.Logger
+}
+
+// Option configures a Scanner.
+type Option func(*Scanner)
+
+// WithTLDs sets the TLDs candidates are built from.
+func WithTLDs(tlds []string) Option {
+	return func(s *Scanner) {
+		s.tlds = make([]string, 0, len(tlds))
+		for _, tld := range tlds {
+			s.tlds = append(s.tlds, strings.ToLower(strings.TrimPrefix(tld, ".")))
+		}
+	}
+}
+
+// WithSuffixList sets the Public Suffix List used to split inputs into a
+// base name and suffix.
+func WithSuffixList(l *SuffixList) Option {
+	return func(s *Scanner) { s.suffixes = l }
+}
+
+// WithSecondLevel also expands every ccTLD into its common second-level
+// public suffixes (co.uk, com.br, ...). A second-level label counts as
+// common when the Public Suffix List uses it under at least minCount
+// ccTLDs and it is not a restricted space such as gov.uk; see
+// SuffixList.SecondLevel. Zero disables the expansion.
+func WithSecondLevel(minCount int) Option {
+	return func(s *Scanner) { s.secondLevel = minCount }
+}
+
+// WithWildcardPolicy sets how candidates matching the wildcard DNS answers
+// of their suffix are handled. The default is WildcardDrop.
+func WithWildcardPolicy(p WildcardPolicy) Option {
+	return func(s *Scanner) { s.wildcard = p }
+}
+
+// WithAllowlist sets the domains, name servers, registrars and registrant
+// organisations known to be owned, and whether matching results are
+// suppressed or tagged as owned.
+func WithAllowlist(l *Allowlist, p AllowlistPolicy) Option {
+	return func(s *Scanner) {
+		s.allowlist = l
+		s.allowPolicy = p
+	}
+}
+
+// WithResolver sets the resolver used for DNS lookups.
+func WithResolver(r Resolver) Option {
+	return func(s *Scanner) { s.resolver = r }
+}
+
+// WithRetries sets how often lookups that time out or fail with SERVFAIL
+// are retried.
+func WithRetries(n int) Option {
+	return func(s *Scanner) {
+		if n >= 0 {
+			s.retries = n
+		}
+	}
+}
+
+// WithDelegationCheck asks the authoritative servers of each suffix for
+// the NS delegation of every candidate, so registered domains without
+// address records are found too.
+func WithDelegationCheck(enabled bool) Option {
+	return func(s *Scanner) { s.delegation = enabled }
+}
+
+// WithRecords collects the NS, MX, TXT, CNAME, SOA and CAA records of
+// every discovered domain. It is enabled by default.
+func WithRecords(enabled bool) Option {
+	return func(s *Scanner) { s.records = enabled }
+}
+
+// WithAttribution compares every variant with a fingerprint of the
+// original domain and classifies it as likely owned, likely third-party or
+// unknown.
+func WithAttribution(enabled bool) Option {
+	return func(s *Scanner) { s.attribution = enabled }
+}
+
+// WithPermutations mutates the base label with the named algorithms (see
+// Algorithms) and crosses every variant with the candidate suffixes.
+func WithPermutations(algorithms ...string) Option {
+	return func(s *Scanner) { s.algorithms = algorithms }
+}
+
+// WithKeyboards sets the layouts used by the adjacent-key algorithms. The
+// default is DefaultKeyboards.
+func WithKeyboards(keyboards ...Keyboard) Option {
+	return func(s *Scanner) { s.permOpts.Keyboards = keyboards }
+}
+
+// WithConfusables sets the lookalike characters of the homograph
+// algorithm. The default is DefaultConfusables.
+func WithConfusables(c Confusables) Option {
+	return func(s *Scanner) { s.permOpts.Confusables = c }
+}
+
+// WithKeywords sets the words of the combosquatting algorithm. The
+// default is DefaultKeywords.
+func WithKeywords(words []string) Option {
+	return func(s *Scanner) { s.permOpts.Keywords = words }
+}
+
+// WithSubdomains sets the host names of the doppelganger algorithm. The
+// subdomains of the scanned input are always added. The default is
+// DefaultSubdomains.
+func WithSubdomains(subdomains []string) Option {
+	return func(s *Scanner) { s.permOpts.Subdomains = subdomains }
+}
+
+// WithPhoneticThreshold sets the minimum similarity, between 0 and 1, of
+// the variants of the phonetic algorithm to the label. The default is
+// DefaultPhoneticThreshold.
+func WithPhoneticThreshold(threshold float64) Option {
+	return func(s *Scanner) { s.permOpts.PhoneticThreshold = threshold }
+}
+
+// WithIDNPolicy restricts internationalised variants to the TLDs whose
+// registry accepts their script. It is enabled by default; disabling it
+// tries every IDN variant under every suffix.
+func WithIDNPolicy(enabled bool) Option {
+	return func(s *Scanner) { s.idnPolicy = enabled }
+}
+
+// WithTLDTypos sets how the TLDs one typo away from the original TLD are
+// used. Typos that are not delegated TLDs are reported with
+// StatusNotRegistrable unless the mode is TLDTyposOff, the default.
+func WithTLDTypos(mode TLDTypoMode) Option {
+	return func(s *Scanner) { s.typoMode = mode }
+}
+
+// WithPermutationTLDs restricts the suffixes crossed with permuted labels.
+// Permuted labels are always tried under the original suffix as well, and
+// the original label is still tried under every candidate suffix.
+func WithPermutationTLDs(tlds []string) Option {
+	return func(s *Scanner) { s.permTLDs = tlds }
+}
+
+// WithEvidence keeps the raw DNS answers and WHOIS/RDAP responses behind
+// each result, with the time of every query, in Result.Evidence.
+func WithEvidence(enabled bool) Option {
+	return func(s *Scanner) { s.evidence = enabled }
+}
+
+// WithWhoisClient sets the WHOIS client. A nil client disables WHOIS lookups.
+func WithWhoisClient(c WhoisClient) Option {
+	return func(s *Scanner) { s.whois = c }
+}
+
+// WithConcurrency sets the number of concurrent workers.
+func WithConcurrency(n int) Option {
+	return func(s *Scanner) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// WithResultHandler registers a callback invoked as soon as a variant is
+// found. It may be called from several goroutines at once.
+func WithResultHandler(fn func(Result)) Option {
+	return func(s *Scanner) { s.onResult = fn }
+}
+
+// WithDebugLog sets the logger used for debugging output.
+func WithDebugLog(l *log.Logger) Option {
+	return func(s *Scanner) { s.debugLog = l }
+}
+
+// WithVerboseLog sets the logger that reports candidates that do not exist.
+func WithVerboseLog(l *log.Logger) Option {
+	return func(s *Scanner) { s.verboseLog = l }
+}
+
+// New returns a Scanner configured with the given options.
+func New(opts ...Option) *Scanner {
+	s := &Scanner{
+		tlds:        DefaultTLDs(),
+		suffixes:    DefaultSuffixList(),
+		resolver:    NewResolver(),
+		retries:     DefaultRetries,
+		records:     true,
+		attribution: true,
+		idnPolicy:   true,
+		whois:       NewWhoisClient(""),
+		concurrency: DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.candidates = s.candidateSuffixes()
+	if ex, ok := s.resolver.(Exchanger); ok {
+		s.exchanger = ex
+	} else {
+		s.exchanger = &DNSResolver{Timeout: dnsTimeout}
+	}
+	return s
+}
+
+// candidateSuffixes returns the suffixes candidates are built from: the
+// configured TLDs, each followed by its second-level suffixes if enabled.
+func (s *Scanner) candidateSuffixes() []string {
+	if s.secondLevel <= 0 {
+		return s.tlds
+	}
+	secondLevel := s.suffixes.SecondLevel(s.secondLevel)
+	out := make([]string, 0, len(s.tlds))
+	for _, tld := range s.tlds {
+		out = append(out, tld)
+		out = append(out, secondLevel[tld]...)
+	}
+	return out
+}
+
+// Scan checks that domain exists and returns every TLD variant of its
+// registrable domain that exists, plus the candidates whose lookup failed
+// after retries so that partial scans are visible. Typo TLDs that are not
+// delegated are reported with StatusNotRegistrable when WithTLDTypos is
+// set. Results are returned in the order they were discovered.
+func (s *Scanner) Scan(ctx context.Context, domain string) ([]Result, error) {
+	domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	baseName, originalTLD := s.extractBaseName(domain)
+	if baseName == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoBaseName, domain)
+	}
+	registrable := baseName + "." + originalTLD
+	s.debugf("Base name: %s, Original TLD: %s", baseName, originalTLD)
+
+	// Check if the original domain exists.
+	if status, _ := s.checkDomain(ctx, domain); !status.Exists() {
+		if status.Failed() {
+			return nil, fmt.Errorf("lookup %s: %s", domain, status)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, domain)
+	}
+
+	var baseline *Fingerprint
+	if s.attribution {
+		baseline = s.Fingerprint(ctx, registrable)
+		s.debugf("Baseline of %s: NS %v, MX %v, registrar %q, registrant %q, IPs %v, ASNs %v",
+			registrable, baseline.NameServers, baseline.MX, baseline.Registrar, baseline.RegistrantOrg, baseline.IPs, baseline.ASNs)
+	}
+
+	var results []Result
+	var resMutex sync.Mutex
+
+	// Create a worker pool for concurrent TLD enumeration.
+	tasks := make(chan Task)
+	var wg sync.WaitGroup
+	for i := 0; i < s.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range tasks {
+				if res, ok := s.processTask(ctx, task); ok {
+					resMutex.Lock()
+					results = append(results, res)
+					resMutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	// Cross the original label and its permutations with the suffixes,
+	// skipping the original domain itself.
+	permOpts := s.permOpts
+	if subs := inputSubdomains(domain, registrable); len(subs) > 0 {
+		if permOpts.Subdomains == nil {
+			permOpts.Subdomains = DefaultSubdomains
+		}
+		permOpts.Subdomains = append(subs, permOpts.Subdomains...)
+	}
+	labels := append([]Permutation{{Label: baseName, Algorithm: AlgorithmTLDSwap}},
+		Permute(baseName, s.algorithms, permOpts)...)
+	candidates, typos, invalid := s.typoSuffixes(originalTLD, s.candidates)
+	s.debugf("Trying %d label(s) for %s", len(labels), registrable)
+	if len(typos) > 0 || len(invalid) > 0 {
+		s.debugf("TLD typos of %s: %d delegated, %d not registrable", originalTLD, len(typos), len(invalid))
+	}
+	var unregistrable []Task
+	for _, tld := range invalid {
+		unregistrable = append(unregistrable, Task{baseName: baseName,
+			candidateTLD: tld, algorithm: AlgorithmTLDTypo, unregistrable: true})
+	}
+	// Permuted labels are always tried under the original suffix, which a
+	// typosquatter is most likely to pick.
+	idnDropped := 0
+	permSuffixes := candidates
+	if len(s.permTLDs) > 0 {
+		permSuffixes = s.permTLDs
+	}
+	if !slices.Contains(permSuffixes, originalTLD) {
+		permSuffixes = append([]string{originalTLD}, permSuffixes...)
+	}
+feed:
+	for _, label := range labels {
+		suffixes := candidates
+		if label.Algorithm != AlgorithmTLDSwap {
+			suffixes = permSuffixes
+		}
+		for _, tld := range suffixes {
+			if label.Unicode != "" && s.idnPolicy && !idnAllowed(tld, label.Unicode) {
+				idnDropped++
+				continue
+			}
+			task := Task{
+				baseName:     label.Label,
+				unicodeName:  label.Unicode,
+				mailHost:     label.MailHost,
+				candidateTLD: tld,
+				algorithm:    label.Algorithm,
+				baseline:     baseline,
+			}
+			if task.algorithm == AlgorithmTLDSwap && typos[tld] {
+				task.algorithm = AlgorithmTLDTypo
+			}
+			if task.Domain() == registrable {
+				continue
+			}
+			select {
+			case tasks <- task:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		if label.Algorithm == AlgorithmTLDSwap {
+			for _, task := range unregistrable {
+				select {
+				case tasks <- task:
+				case <-ctx.Done():
+					break feed
+				}
+			}
+		}
+	}
+	close(tasks)
+	if idnDropped > 0 {
+		s.notef("IDN policy skipped %d homograph candidate(s) whose scripts the TLD does not accept", idnDropped)
+	}
+	wg.Wait()
+
+	return results, ctx.Err()
+}
+
+// processTask performs DNS and WHOIS lookups for a candidate domain.
+func (s *Scanner) processTask(ctx context.Context, task Task) (Result, bool) {
+	candidateDomain := task.Domain()
+	if task.unregistrable {
+		res := Result{
+			Domain:    candidateDomain,
+			Status:    StatusNotRegistrable,
+			Algorithm: task.algorithm,
+			Reason:    "." + task.candidateTLD + " is not a registrable suffix",
+		}
+		s.emit(res)
+		return res, true
+	}
+	if s.allowPolicy == AllowlistSuppress && s.allowlist.matchDomain(candidateDomain) {
+		// Listed domains need no lookups at all.
+		s.suppress(candidateDomain, "domain "+candidateDomain)
+		return Result{}, false
+	}
+	var elog *evidenceLog
+	if s.evidence {
+		elog = &evidenceLog{}
+	}
+	ctx = withEvidence(ctx, elog)
+	status, ips := s.checkDomain(ctx, candidateDomain)
+	res := Result{
+		Domain:           candidateDomain,
+		Status:           status,
+		IPs:              ips,
+		Algorithm:        task.algorithm,
+		MailDoppelganger: task.mailHost,
+	}
+	if task.unicodeName != "" {
+		res.UnicodeDomain = task.unicodeName + "." + task.candidateTLD
+	}
+	if s.delegation {
+		res.Delegation, res.DelegationNS = s.checkDelegation(ctx, candidateDomain, task.candidateTLD)
+	}
+	delegated := res.Delegation == DelegationFound
+	if status == StatusNXDomain && !delegated {
+		if s.verboseLog != nil {
+			s.verboseLog.Printf("Domain %s does not exist.", candidateDomain)
+		}
+		return Result{}, false
+	}
+
+	if status.Failed() && !delegated {
+		// Report the failure rather than silently dropping the candidate.
+		res.Reason = "lookup failed: " + string(status)
+		res.Evidence = elog.evidence()
+		s.emit(res)
+		return res, true
+	}
+	// A delegation at the registry is proof enough; wildcard answers only
+	// matter for candidates known through resolution alone.
+	if !delegated && s.wildcard != WildcardOff && s.matchesWildcard(ctx, task.candidateTLD, ips) {
+		if s.wildcard == WildcardDrop {
+			if s.verboseLog != nil {
+				s.verboseLog.Printf("Domain %s only matches wildcard DNS of .%s.", candidateDomain, task.candidateTLD)
+			}
+			return Result{}, false
+		}
+		res.Wildcard = true
+		res.Reason = "answers match wildcard DNS of ." + task.candidateTLD
+	}
+	if s.records {
+		res.Records = s.collectRecords(ctx, candidateDomain)
+	}
+	if s.whois != nil {
+		rec, err := s.whois.Lookup(ctx, candidateDomain)
+		if err != nil {
+			s.debugf("WHOIS lookup for %s failed: %v", candidateDomain, err)
+			res.WhoisError = err.Error()
+			res.WhoisIncomplete = errors.Is(err, ErrWhoisRateLimited)
+		}
+		if rec != nil {
+			res.WhoisIncomplete = res.WhoisIncomplete || rec.Incomplete
+			res.Whois = rec
+			res.WhoisServer = rec.Server
+			res.WhoisReferral = rec.Referral
+			res.Registrant = rec.Registrant
+			res.Privacy, res.PrivacyService = rec.Privacy()
+			res.Server = rec.Registrar
+		}
+	}
+	if rule, ok := s.allowlist.Match(res); ok {
+		if s.allowPolicy == AllowlistSuppress {
+			s.suppress(candidateDomain, rule)
+			return Result{}, false
+		}
+		res.Ownership, res.OwnershipSignals = OwnershipOwned, []string{"allowlist: " + rule}
+	} else if task.baseline != nil {
+		recs := res.Records
+		if recs == nil {
+			recs = s.collectRecords(ctx, candidateDomain)
+		}
+		variant := s.fingerprint(ctx, candidateDomain, ips, recs, res.Whois)
+		res.Ownership, res.OwnershipSignals = s.attribute(task.baseline, variant)
+	}
+	res.Evidence = elog.evidence()
+	s.emit(res)
+	return res, true
+}
+
+// suppress counts a result dropped by the allowlist.
+func (s *Scanner) suppress(domain, rule string) {
+	s.suppressed.Add(1)
+	if s.verboseLog != nil {
+		s.verboseLog.Printf("Domain %s is allowlisted (%s).", domain, rule)
+	}
+}
+
+// Suppressed returns how many results the allowlist has suppressed so far.
+func (s *Scanner) Suppressed() int {
+	return int(s.suppressed.Load())
+}
+
+// emit passes a result to the result handler, if one is configured.
+func (s *Scanner) emit(res Result) {
+	if s.onResult != nil {
+		s.onResult(res)
+	}
+}
+
+// notef reports a scan-wide notice to the verbose logger, or to the debug
+// logger when verbose output is off.
+func (s *Scanner) notef(format string, args ...interface{}) {
+	if s.verboseLog != nil {
+		s.verboseLog.Printf(format, args...)
+		return
+	}
+	s.debugf(format, args...)
+}
+
+// debugf writes to the debug logger, if one is configured.
+func (s *Scanner) debugf(format string, args ...interface{}) {
+	if s.debugLog != nil {
+		s.debugLog.Printf(format, args...)
+	}
+}
@@ -1,34 +1,4 @@
-package main
-
-import (
-	"bufio"
-	"context"
-	"encoding/json"
-	"flag"
-	"fmt"
-	"log"
-	"net"
-	"os"
-	"regexp"
-	"strings"
-	"sync"
-	"time"
-)
-
-// Result holds information about a discovered domain.
-type Result struct {
-	Domain     string   `json:"domain"`
-	IPs        []string `json:"ips"`
-	Registrant string   `json:"registrant"`
-	Server     string   `json:"server"`
-}
-
-// Task defines a candidate domain lookup task.
-type Task struct {
-	baseName     string
-	original     string
-	candidateTLD string
-}
+package tldbuster
 
 // tldSlice contains the list of TLDs (you can update or replace this list as needed).
 var tldSlice = []string{
@@ -219,286 +189,7 @@ var tldSlice = []string{
 	"zara", "zero", "zip", "zm", "zone", "zuerich", "zw",
 }
 
-// tldMap is a map for fast TLD lookups.
-var tldMap map[string]struct{}
-
-// initTLDMap initializes the TLD lookup map.
-func initTLDMap() {
-	tldMap = make(map[string]struct{}, len(tldSlice))
-	for _, tld := range tldSlice {
-		tldLower := strings.ToLower(tld)
-		tldMap[tldLower] = struct{}{}
-	}
-}
-
-func main() {
-	// Define command-line flags.
-	domain := flag.String("d", "", "Domain to test against (single target)")
-	domainList := flag.String("dL", "", "List of targets (e.g., targets.txt)")
-	silent := flag.Bool("s", false, "Silent output")
-	verbose := flag.Bool("v", false, "Verbose output")
-	outputFile := flag.String("o", "", "Output file (json or txt)")
-	debug := flag.Bool("debug", false, "Debugging mode")
-	flag.Parse()
-
-	if !*silent {
-		printBanner()
-	}
-
-	// Validate input.
-	if (*domain == "" && *domainList == "") || (*domain != "" && *domainList != "") {
-		fmt.Println("Please specify either -d or -dL, but not both.")
-		os.Exit(1)
-	}
-
-	// Load domains to test.
-	var domains []string
-	if *domain != "" {
-		domains = append(domains, *domain)
-	} else {
-		file, err := os.Open(*domainList)
-		if err != nil {
-			log.Fatalf("Error opening file: %v", err)
-		}
-		defer file.Close()
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line != "" {
-				domains = append(domains, line)
-			}
-		}
-		if err := scanner.Err(); err != nil {
-			log.Fatalf("Error reading file: %v", err)
-		}
-	}
-
-	// Initialize TLD map.
-	initTLDMap()
-
-	var results []Result
-	var resMutex sync.Mutex
-
-	// Create a worker pool for concurrent TLD enumeration.
-	const workerCount = 20
-	tasks := make(chan Task)
-	var wg sync.WaitGroup
-
-	// Launch worker goroutines.
-	for i := 0; i < workerCount; i++ {
-		go func() {
-			for task := range tasks {
-				processTask(task, *silent, *verbose, *debug, &results, &resMutex)
-				wg.Done()
-			}
-		}()
-	}
-
-	// Process each input domain.
-	for _, domainName := range domains {
-		baseName, originalTLD := extractBaseName(domainName)
-		if baseName == "" {
-			if *debug {
-				log.Printf("Could not extract base name from domain: %s", domainName)
-			}
-			continue
-		}
-		if *debug {
-			log.Printf("Base name: %s, Original TLD: %s", baseName, originalTLD)
-		}
-
-		// Check if the original domain exists.
-		if exists, _ := checkDomain(domainName); !exists {
-			if *debug {
-				log.Printf("Domain %s does not exist.", domainName)
-			}
-			continue
-		}
-
-		// For each TLD in the list, create a task (skip the original TLD).
-		for _, tld := range tldSlice {
-			candidateDomain := baseName + "." + strings.ToLower(tld)
-			if candidateDomain == domainName {
-				continue
-			}
-			wg.Add(1)
-			tasks <- Task{
-				baseName:     baseName,
-				original:     domainName,
-				candidateTLD: strings.ToLower(tld),
-			}
-		}
-	}
-
-	wg.Wait()
-	close(tasks)
-
-	// Output results.
-	if !*silent {
-		for _, result := range results {
-			fmt.Printf("\033[31mDomain: %s\033[0m\n", result.Domain)
-			fmt.Printf("IPs: %v\n", result.IPs)
-			fmt.Printf("Registrant: %s\n", result.Registrant)
-			fmt.Printf("Server: %s\n\n", result.Server)
-		}
-	}
-	if *outputFile != "" {
-		if err := outputResults(results, *outputFile); err != nil {
-			log.Printf("Error writing output: %v", err)
-		}
-	}
-}
-
-// processTask performs DNS and WHOIS lookups for a candidate domain.
-func processTask(task Task, silent bool, verbose bool, debug bool, results *[]Result, resMutex *sync.Mutex) {
-	candidateDomain := task.baseName + "." + task.candidateTLD
-	if exists, ips := checkDomain(candidateDomain); exists {
-		registrant, server := performWhois(candidateDomain, debug)
-		res := Result{
-			Domain:     candidateDomain,
-			IPs:        ips,
-			Registrant: registrant,
-			Server:     server,
-		}
-		resMutex.Lock()
-		*results = append(*results, res)
-		resMutex.Unlock()
-
-		if !silent {
-			fmt.Printf("\033[31mDomain: %s\033[0m\n", candidateDomain)
-			fmt.Printf("IPs: %v\n", ips)
-			fmt.Printf("Registrant: %s\n", registrant)
-			fmt.Printf("Server: %s\n\n", server)
-		}
-	} else if verbose {
-		log.Printf("Domain %s does not exist.", candidateDomain)
-	}
-}
-
-// printBanner displays the tool banner.
-func printBanner() {
-	cyan := "\033[36m"
-	reset := "\033[0m"
-	banner := `
- ========================================
-   TLDBuster by r3dcl1ff @Redflare-Cyber
- ========================================
-`
-	fmt.Printf("%s%s%s\n", cyan, banner, reset)
-}
-
-// extractBaseName returns the base name and TLD from a given domain.
-func extractBaseName(domain string) (string, string) {
-	domain = strings.ToLower(domain)
-	labels := strings.Split(domain, ".")
-	// Iterate from the rightmost label backward.
-	for i := 1; i <= len(labels); i++ {
-		possibleTLD := strings.Join(labels[len(labels)-i:], ".")
-		if _, ok := tldMap[possibleTLD]; ok {
-			baseName := strings.Join(labels[:len(labels)-i], ".")
-			return baseName, possibleTLD
-		}
-	}
-	return "", ""
-}
-
-// checkDomain uses a context with timeout to resolve the domain.
-func checkDomain(domain string) (bool, []string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	ips, err := net.DefaultResolver.LookupIPAddr(ctx, domain)
-	if err != nil {
-		return false, nil
-	}
-	var ipStrs []string
-	for _, ip := range ips {
-		ipStrs = append(ipStrs, ip.IP.String())
-	}
-	return true, ipStrs
-}
-
-// performWhois queries the WHOIS server and extracts registrant and registrar details.
-func performWhois(domain string, debug bool) (string, string) {
-	tld := getTLD(domain)
-	// Using a simple mapping; you can extend this for TLDs with different WHOIS servers.
-	whoisServer := "whois.nic." + tld
-
-	conn, err := net.DialTimeout("tcp", whoisServer+":43", 5*time.Second)
-	if err != nil {
-		if debug {
-			log.Printf("Error connecting to WHOIS server for %s: %v", domain, err)
-		}
-		return "", ""
-	}
-	defer conn.Close()
-
-	_, err = conn.Write([]byte(domain + "\r\n"))
-	if err != nil {
-		if debug {
-			log.Printf("Error writing to WHOIS server for %s: %v", domain, err)
-		}
-		return "", ""
-	}
-
-	var resultBuilder strings.Builder
-	scanner := bufio.NewScanner(conn)
-	for scanner.Scan() {
-		resultBuilder.WriteString(scanner.Text() + "\n")
-	}
-	if err := scanner.Err(); err != nil && debug {
-		log.Printf("Error reading WHOIS response for %s: %v", domain, err)
-	}
-	response := resultBuilder.String()
-
-	// Use regex with case-insensitive matching to extract fields.
-	registrant := extractField(response, `(?i)Registrant Name:\s*(.*)`)
-	if registrant == "" {
-		registrant = extractField(response, `(?i)Admin Name:\s*(.*)`)
-	}
-	server := extractField(response, `(?i)Registrar:\s*(.*)`)
-
-	return registrant, server
-}
-
-// getTLD extracts the TLD from a domain.
-func getTLD(domain string) string {
-	parts := strings.Split(domain, ".")
-	if len(parts) < 2 {
-		return ""
-	}
-	return parts[len(parts)-1]
-}
-
-// extractField uses regex to extract a field from WHOIS data.
-func extractField(data, pattern string) string {
-	re := regexp.MustCompile(pattern)
-	match := re.FindStringSubmatch(data)
-	if len(match) > 1 {
-		return strings.TrimSpace(match[1])
-	}
-	return ""
-}
-
-// outputResults writes the results to a file in JSON or plain text format.
-func outputResults(results []Result, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	if strings.HasSuffix(strings.ToLower(filename), ".json") {
-		encoder := json.NewEncoder(file)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(results)
-	}
-
-	// TXT format.
-	for _, result := range results {
-		file.WriteString(fmt.Sprintf("Domain: %s\n", result.Domain))
-		file.WriteString(fmt.Sprintf("IPs: %v\n", result.IPs))
-		file.WriteString(fmt.Sprintf("Registrant: %s\n", result.Registrant))
-		file.WriteString(fmt.Sprintf("Server: %s\n\n", result.Server))
-	}
-	return nil
+// DefaultTLDs returns a copy of the built-in TLD list.
+func DefaultTLDs() []string {
+	return append([]string(nil), tldSlice...)
 }
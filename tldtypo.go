@@ -0,0 +1,100 @@
+package tldbuster
+
+import "strings"
+
+// AlgorithmTLDTypo marks results that keep the original label under a TLD
+// one typo away from the original TLD.
+const AlgorithmTLDTypo = "tld-typo"
+
+// StatusNotRegistrable is reported for typo suffixes no domain can be
+// registered under: TLDs missing from the root zone, or second-level
+// suffixes like "co.uj" the Public Suffix List does not know.
+const StatusNotRegistrable LookupStatus = "not-registrable"
+
+// TLDTypoMode decides how the TLDs one typo away from the original TLD,
+// e.g. .co, .cm, .om and .con for .com, are used.
+type TLDTypoMode int
+
+const (
+	// TLDTyposOff treats every candidate suffix the same.
+	TLDTyposOff TLDTypoMode = iota
+	// TLDTyposRank tries the typo TLDs first, then the other candidates.
+	TLDTyposRank
+	// TLDTyposOnly restricts the candidates to the typo TLDs.
+	TLDTyposOnly
+)
+
+// tldTypoAlgorithms are the typos considered for TLDs. Insertions are left
+// out of the typos reported as not registrable, as they are too many to be
+// common.
+var (
+	tldTypoAlgorithms   = []string{"omission", "repetition", "transposition", "replacement", "insertion"}
+	tldInvalidAlgorithm = map[string]bool{"omission": true, "repetition": true, "transposition": true, "replacement": true}
+)
+
+// tldTypos returns the suffixes with a typo in the TLD of suffix that
+// domains can be registered under, and the common ones they cannot. The
+// second-level part of a suffix is kept, so "co.uk" gives "co.uj": such a
+// typo is registrable when the Public Suffix List knows it as a suffix.
+// TLDs are alphabetic, so typos with digits or hyphens are skipped.
+func (s *Scanner) tldTypos(suffix string) (delegated, invalid []string) {
+	sld, tld := "", suffix
+	if i := strings.LastIndex(suffix, "."); i >= 0 {
+		sld, tld = suffix[:i+1], suffix[i+1:]
+	}
+	known := make(map[string]bool, len(tldSlice)+len(s.tlds))
+	for _, t := range tldSlice {
+		known[t] = true
+	}
+	for _, t := range s.tlds {
+		known[t] = true
+	}
+	for _, p := range Permute(tld, tldTypoAlgorithms, s.permOpts) {
+		if strings.Trim(p.Label, "abcdefghijklmnopqrstuvwxyz") != "" {
+			continue
+		}
+		typo := sld + p.Label
+		registrable := known[p.Label]
+		if registrable && sld != "" {
+			public, _ := s.suffixes.PublicSuffix("example." + typo)
+			registrable = public == typo
+		}
+		switch {
+		case registrable:
+			delegated = append(delegated, typo)
+		case tldInvalidAlgorithm[p.Algorithm]:
+			invalid = append(invalid, typo)
+		}
+	}
+	return delegated, invalid
+}
+
+// typoSuffixes orders or restricts candidates according to the TLD typo
+// mode and returns the typo suffixes that are registrable and not. In
+// TLDTyposOnly mode the candidates that are not typos are left out, which
+// is logged as it also drops the second-level suffixes of WithSecondLevel.
+func (s *Scanner) typoSuffixes(suffix string, candidates []string) (ordered []string, typos map[string]bool, invalid []string) {
+	if s.typoMode == TLDTyposOff {
+		return candidates, nil, nil
+	}
+	delegated, invalid := s.tldTypos(suffix)
+	typos = make(map[string]bool, len(delegated))
+	for _, t := range delegated {
+		typos[t] = true
+	}
+	ordered = delegated
+	skipped := 0
+	for _, c := range candidates {
+		switch {
+		case typos[c] || c == suffix:
+		case s.typoMode == TLDTyposRank:
+			ordered = append(ordered, c)
+		default:
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		s.notef("TLD typo mode: skipping %d candidate suffix(es) that are not typos of .%s", skipped, suffix)
+	}
+	return ordered, typos, invalid
+}
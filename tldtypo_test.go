@@ -0,0 +1,63 @@
+package tldbuster
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTLDTyposSecondLevel(t *testing.T) {
+	s := New(WithTLDTypos(TLDTyposOnly))
+	tests := []struct {
+		suffix      string
+		delegated   []string
+		invalid     []string
+		notExpected []string
+	}{
+		{"co.uk", nil, []string{"co.uj", "co.ul", "co.u", "co.ku"}, []string{"co.uk", "uj", "k"}},
+		{"com.br", []string{"com.bt", "com.gr"}, []string{"com.b", "com.rb"}, []string{"com.br", "bt", "r"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.suffix, func(t *testing.T) {
+			delegated, invalid := s.tldTypos(tt.suffix)
+			sld := tt.suffix[:strings.LastIndex(tt.suffix, ".")+1]
+			for _, typo := range append(slices.Clone(delegated), invalid...) {
+				if !strings.HasPrefix(typo, sld) {
+					t.Errorf("typo %q lost the second-level part %q", typo, sld)
+				}
+			}
+			for _, want := range tt.delegated {
+				if !slices.Contains(delegated, want) {
+					t.Errorf("registrable typos %v lack %q", delegated, want)
+				}
+			}
+			for _, want := range tt.invalid {
+				if !slices.Contains(invalid, want) {
+					t.Errorf("unregistrable typos %v lack %q", invalid, want)
+				}
+			}
+			for _, bad := range tt.notExpected {
+				if slices.Contains(delegated, bad) || slices.Contains(invalid, bad) {
+					t.Errorf("unexpected typo %q", bad)
+				}
+			}
+		})
+	}
+}
+
+func TestTypoSuffixesKeepsCandidateTypos(t *testing.T) {
+	s := New(WithTLDTypos(TLDTyposOnly))
+	ordered, typos, _ := s.typoSuffixes("com.br", []string{"com.br", "com.bt", "br", "de"})
+	if !typos["com.bt"] || !slices.Contains(ordered, "com.bt") {
+		t.Errorf("com.bt missing from %v", ordered)
+	}
+	if slices.Contains(ordered, "de") || slices.Contains(ordered, "com.br") {
+		t.Errorf("only mode kept non-typo suffixes: %v", ordered)
+	}
+
+	s = New(WithTLDTypos(TLDTyposRank))
+	ordered, _, _ = s.typoSuffixes("com.br", []string{"com.br", "de", "com.bt"})
+	if last := ordered[len(ordered)-1]; last != "de" {
+		t.Errorf("rank mode should try typos first, got %v", ordered)
+	}
+}
@@ -0,0 +1,216 @@
+package tldbuster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// whoisTimeout bounds connecting to a WHOIS server.
+const whoisTimeout = 5 * time.Second
+
+// whoisReadTimeout bounds a whole WHOIS exchange when the context carries
+// no deadline of its own.
+const whoisReadTimeout = 15 * time.Second
+
+// maxWhoisResponse caps how much of a WHOIS response is read.
+const maxWhoisResponse = 1 << 20
+
+// maxWhoisReferrals is how many registrar referrals are followed.
+const maxWhoisReferrals = 1
+
+// whoisReferralRe matches the registrar referral of thin registries.
+var whoisReferralRe = regexp.MustCompile(`(?im)^\s*(?:Registrar WHOIS Server|Whois Server):\s*(\S+)`)
+
+// WhoisRecord holds the registration details of a domain, as extracted
+// from a WHOIS response or an RDAP domain object.
+type WhoisRecord struct {
+	// Source is "whois" or "rdap".
+	Source            string     `json:"source"`
+	Registrant        string     `json:"registrant,omitempty"`
+	RegistrantOrg     string     `json:"registrant_org,omitempty"`
+	RegistrantCountry string     `json:"registrant_country,omitempty"`
+	RegistrantEmail   string     `json:"registrant_email,omitempty"`
+	Registrar         string     `json:"registrar,omitempty"`
+	AbuseEmail        string     `json:"abuse_email,omitempty"`
+	AbusePhone        string     `json:"abuse_phone,omitempty"`
+	Created           *time.Time `json:"created,omitempty"`
+	Updated           *time.Time `json:"updated,omitempty"`
+	Expires           *time.Time `json:"expires,omitempty"`
+	// Status holds the EPP status codes, e.g. "clientTransferProhibited".
+	Status      []string `json:"status,omitempty"`
+	NameServers []string `json:"name_servers,omitempty"`
+	Events      []Event  `json:"events,omitempty"`
+	// Server is the registry WHOIS server or RDAP base URL that was queried.
+	Server string `json:"server,omitempty"`
+	// Referral is the registrar server followed from the registry
+	// response, if any.
+	Referral string `json:"referral,omitempty"`
+	// Incomplete is set when part of the data could not be fetched, e.g.
+	// because the registrar server kept rate limiting us.
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// Event is a dated lifecycle event of a registration, such as
+// "registration" or "expiration".
+type Event struct {
+	Action string    `json:"action"`
+	Date   time.Time `json:"date"`
+}
+
+// empty reports whether the record carries no registration details.
+func (r *WhoisRecord) empty() bool {
+	return r == nil || (r.Registrant == "" && r.Registrar == "")
+}
+
+// WhoisClient looks up WHOIS information for a domain.
+type WhoisClient interface {
+	Lookup(ctx context.Context, domain string) (*WhoisRecord, error)
+}
+
+// PortWhois is a WhoisClient speaking the port-43 WHOIS protocol.
+type PortWhois struct {
+	// Dialer is used to connect to WHOIS servers. Nil means a net.Dialer
+	// with a short timeout.
+	Dialer interface {
+		DialContext(ctx context.Context, network, address string) (net.Conn, error)
+	}
+	// IANAServer is asked for the WHOIS server of each TLD. Empty means
+	// whois.iana.org; a host:port pointing at a local stub works for tests.
+	IANAServer string
+	// Overrides maps a TLD to its WHOIS server, bypassing discovery.
+	// Servers may carry a port; 43 is used otherwise.
+	Overrides map[string]string
+	// Charsets maps a WHOIS server to the charset of its responses, e.g.
+	// "iso-8859-1" or "shift_jis". Other servers are detected.
+	Charsets map[string]string
+	// QueryTemplates maps a WHOIS server to its query format, with "%s"
+	// standing for the domain, e.g. "-T dn %s".
+	QueryTemplates map[string]string
+	// Parsers maps a TLD to the parser of its WHOIS responses, taking
+	// precedence over the built-in per-registry parsers.
+	Parsers map[string]WhoisParser
+	// MaxPerServer caps the concurrent queries to a single server. Zero
+	// means no limit.
+	MaxPerServer int
+	// Interval is the minimum delay between two queries to the same server.
+	Interval time.Duration
+	// Retries is how often a rate-limited query is retried, with
+	// exponential backoff, before giving up.
+	Retries int
+
+	cache     whoisServerCache
+	referrals suffixCache[whoisReferral]
+	limiter   whoisLimiter
+}
+
+// NewWhoisClient returns a port-43 WHOIS client with the default per-server
+// limits. Discovered WHOIS servers are cached in cachePath; an empty path
+// keeps them in memory only.
+func NewWhoisClient(cachePath string) *PortWhois {
+	return &PortWhois{
+		MaxPerServer: DefaultWhoisPerServer,
+		Interval:     DefaultWhoisInterval,
+		Retries:      DefaultWhoisRetries,
+		cache:        whoisServerCache{path: cachePath},
+	}
+}
+
+// Lookup queries the WHOIS server and extracts registrant and registrar details.
+func (w *PortWhois) Lookup(ctx context.Context, domain string) (*WhoisRecord, error) {
+	tld := getTLD(domain)
+	whoisServer, err := w.whoisServer(ctx, tld)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", tld, err)
+	}
+
+	// A read error may still leave a usable partial response.
+	response, err := w.limitedQuery(ctx, whoisServer, w.queryString(whoisServer, domain))
+	if response == "" {
+		return nil, err
+	}
+	referral, response, complete := w.followReferrals(ctx, domain, whoisServer, response)
+
+	rec := w.whoisParser(tld)(response)
+	rec.Server = whoisServer
+	rec.Referral = referral
+	rec.Incomplete = err != nil || !complete
+	return rec, err
+}
+
+// followReferrals follows the registrar referral of a thin registry
+// response and returns the last server reached together with the merged
+// responses. The registrar part comes first so its fields win during
+// extraction. Servers already visited are never queried again. complete is
+// false when a registrar kept rate limiting the query.
+func (w *PortWhois) followReferrals(ctx context.Context, domain, server, response string) (last, merged string, complete bool) {
+	visited := map[string]bool{strings.ToLower(server): true}
+	merged, complete = response, true
+	for hop := 0; hop < maxWhoisReferrals; hop++ {
+		next := referralServer(response)
+		if next == "" || visited[strings.ToLower(next)] {
+			break
+		}
+		visited[strings.ToLower(next)] = true
+
+		// Keep what the registry said when the registrar is unreachable.
+		var err error
+		if response, err = w.limitedQuery(ctx, next, w.queryString(next, domain)); response == "" {
+			complete = !errors.Is(err, ErrWhoisRateLimited)
+			break
+		}
+		merged = response + "\n" + merged
+		last = next
+	}
+	return last, merged, complete
+}
+
+// referralServer extracts the registrar WHOIS server from a response.
+func referralServer(response string) string {
+	m := whoisReferralRe.FindStringSubmatch(response)
+	if m == nil {
+		return ""
+	}
+	server := strings.TrimPrefix(strings.ToLower(m[1]), "whois://")
+	if strings.Contains(server, "://") {
+		// Only port-43 referrals can be followed.
+		return ""
+	}
+	return strings.TrimSuffix(server, "/")
+}
+
+// query sends a single WHOIS query and returns the response decoded to
+// UTF-8 with LF line endings, together with the bytes as received.
+func (w *PortWhois) query(ctx context.Context, server, q string) (string, []byte, error) {
+	dialer := w.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{Timeout: whoisTimeout}
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", withPort(server, "43"))
+	if err != nil {
+		return "", nil, err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(whoisReadTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte(q + "\r\n")); err != nil {
+		return "", nil, err
+	}
+
+	// Keep whatever arrived before a read error.
+	raw, err := io.ReadAll(io.LimitReader(conn, maxWhoisResponse))
+	response, derr := decodeWhois(raw, w.charset(server))
+	if err == nil {
+		err = derr
+	}
+	return strings.ReplaceAll(response, "\r\n", "\n"), raw, err
+}
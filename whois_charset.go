@@ -0,0 +1,127 @@
+package tldbuster
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// whoisCharsets are the charsets of servers whose responses cannot be
+// detected reliably.
+var whoisCharsets = map[string]string{
+	"whois.jprs.jp": "iso-2022-jp",
+}
+
+// whoisQueryTemplates are the query formats of servers that need flags to
+// return the domain record in a parseable form. "%s" is the domain.
+var whoisQueryTemplates = map[string]string{
+	"whois.denic.de": "-T dn %s",
+	"whois.jprs.jp":  "%s/e",
+}
+
+// charset returns the configured charset of server, or "" to detect it.
+func (w *PortWhois) charset(server string) string {
+	server = strings.ToLower(server)
+	if cs, ok := w.Charsets[server]; ok {
+		return cs
+	}
+	return whoisCharsets[server]
+}
+
+// queryString formats the query for domain as server expects it.
+func (w *PortWhois) queryString(server, domain string) string {
+	server = strings.ToLower(server)
+	tmpl, ok := w.QueryTemplates[server]
+	if !ok {
+		tmpl, ok = whoisQueryTemplates[server]
+	}
+	if !ok || !strings.Contains(tmpl, "%s") {
+		return domain
+	}
+	return strings.Replace(tmpl, "%s", domain, 1)
+}
+
+// decodeWhois transcodes a raw response to UTF-8. An empty or "auto"
+// charset keeps valid UTF-8 as is and otherwise guesses between the
+// Japanese encodings and ISO-8859-1.
+func decodeWhois(raw []byte, charset string) (string, error) {
+	var enc encoding.Encoding
+	switch cs := strings.ToLower(charset); cs {
+	case "", "auto":
+		enc = detectCharset(raw)
+	default:
+		var err error
+		if enc, err = htmlindex.Get(cs); err != nil {
+			return string(raw), fmt.Errorf("WHOIS charset %q: %w", charset, err)
+		}
+	}
+	if enc == nil {
+		return string(raw), nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(raw)
+	if err != nil {
+		return string(raw), err
+	}
+	return string(decoded), nil
+}
+
+// detectCharset guesses the encoding of a response. It returns nil for
+// UTF-8, including plain ASCII.
+func detectCharset(raw []byte) encoding.Encoding {
+	switch {
+	case bytes.Contains(raw, []byte("\x1b$B")) || bytes.Contains(raw, []byte("\x1b$@")):
+		return japanese.ISO2022JP
+	case utf8.Valid(raw):
+		return nil
+	}
+	euc, sjis := japaneseScores(raw)
+	switch {
+	case euc > 0 && euc >= sjis:
+		return japanese.EUCJP
+	case sjis > 0:
+		return japanese.ShiftJIS
+	default:
+		return charmap.ISO8859_1
+	}
+}
+
+// japaneseScores counts the byte pairs that are valid double-byte
+// characters in EUC-JP and Shift_JIS. Either count drops to zero as soon as
+// a byte sequence is impossible in that encoding. Shift_JIS pairs with an
+// ASCII trail byte are allowed but not counted: an accented ISO-8859-1
+// letter followed by a plain one looks just the same.
+func japaneseScores(raw []byte) (euc, sjis int) {
+	for i := 0; i < len(raw) && euc >= 0; i++ {
+		c := raw[i]
+		switch {
+		case c < 0x80:
+		case (c >= 0xa1 && c <= 0xfe || c == 0x8e) && i+1 < len(raw) && raw[i+1] >= 0xa1 && raw[i+1] <= 0xfe:
+			euc++
+			i++
+		default:
+			euc = -1
+		}
+	}
+	for i := 0; i < len(raw) && sjis >= 0; i++ {
+		c := raw[i]
+		switch {
+		case c < 0x80, c >= 0xa1 && c <= 0xdf:
+			// ASCII and half-width katakana.
+		case (c >= 0x81 && c <= 0x9f || c >= 0xe0 && c <= 0xfc) && i+1 < len(raw) &&
+			(raw[i+1] >= 0x40 && raw[i+1] <= 0x7e || raw[i+1] >= 0x80 && raw[i+1] <= 0xfc):
+			if raw[i+1] >= 0x80 {
+				sjis++
+			}
+			i++
+		default:
+			sjis = -1
+		}
+	}
+	return max(euc, 0), max(sjis, 0)
+}
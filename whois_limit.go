@@ -0,0 +1,167 @@
+package tldbuster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWhoisPerServer is how many queries may run at once against a
+	// single WHOIS server.
+	DefaultWhoisPerServer = 2
+	// DefaultWhoisInterval is the minimum spacing between two queries sent
+	// to the same WHOIS server.
+	DefaultWhoisInterval = 500 * time.Millisecond
+	// DefaultWhoisRetries is how often a rate-limited query is retried.
+	DefaultWhoisRetries = 3
+)
+
+// whoisBackoff is the delay before the first retry of a rate-limited query;
+// it doubles on every further attempt.
+const whoisBackoff = 2 * time.Second
+
+// maxRateLimitResponse bounds the size of a response that may be taken for
+// a rate-limit notice. Real records quoting similar wording in their terms
+// of use are much longer.
+const maxRateLimitResponse = 1024
+
+// ErrWhoisRateLimited is returned when a WHOIS server keeps refusing
+// queries because of rate limits or quotas.
+var ErrWhoisRateLimited = errors.New("WHOIS server rate limit exceeded")
+
+// rateLimitRe matches the notices servers send instead of data when a
+// client is throttled.
+var rateLimitRe = regexp.MustCompile(`(?i)` + strings.Join([]string{
+	`limit (?:exceeded|reached)`,
+	`rate limit`,
+	`quota (?:exceeded|has been exceeded|for .* has been exceeded)`,
+	`too many (?:requests|queries|connections)`,
+	`exceeded (?:the )?(?:maximum|allowed|query)`,
+	`maximum (?:query|queries|number of queries)`,
+	`try again later`,
+	`temporarily (?:blocked|banned|denied)`,
+	`access control limit`,
+}, "|"))
+
+// rateLimited reports whether a response is a rate-limit notice rather
+// than a record.
+func rateLimited(response string) bool {
+	return len(response) <= maxRateLimitResponse && rateLimitRe.MatchString(response)
+}
+
+// whoisGate paces the queries sent to one WHOIS server.
+type whoisGate struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// whoisLimiter holds a gate per WHOIS server.
+type whoisLimiter struct {
+	mu    sync.Mutex
+	gates map[string]*whoisGate
+}
+
+// gate returns the gate of server, allowing perServer concurrent queries.
+func (l *whoisLimiter) gate(server string, perServer int) *whoisGate {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.gates == nil {
+		l.gates = make(map[string]*whoisGate)
+	}
+	key := strings.ToLower(server)
+	g, ok := l.gates[key]
+	if !ok {
+		g = &whoisGate{}
+		if perServer > 0 {
+			g.slots = make(chan struct{}, perServer)
+		}
+		l.gates[key] = g
+	}
+	return g
+}
+
+// acquire waits for a free slot and for the pacing interval to pass. The
+// returned function releases the slot.
+func (g *whoisGate) acquire(ctx context.Context, interval time.Duration) (func(), error) {
+	release := func() {}
+	if g.slots != nil {
+		select {
+		case g.slots <- struct{}{}:
+			release = func() { <-g.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	g.mu.Lock()
+	now := time.Now()
+	start := g.next
+	if start.Before(now) {
+		start = now
+	}
+	g.next = start.Add(interval)
+	g.mu.Unlock()
+
+	if err := sleepContext(ctx, time.Until(start)); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// delay pushes the next query to the server back by d, so that every
+// worker backs off once the server has complained.
+func (g *whoisGate) delay(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if until := time.Now().Add(d); g.next.Before(until) {
+		g.next = until
+	}
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limitedQuery sends a query through the per-server limits, retrying with
+// exponential backoff while the server answers with a rate-limit notice.
+func (w *PortWhois) limitedQuery(ctx context.Context, server, q string) (string, error) {
+	g := w.limiter.gate(server, w.MaxPerServer)
+	backoff := whoisBackoff
+	for attempt := 0; ; attempt++ {
+		release, err := g.acquire(ctx, w.Interval)
+		if err != nil {
+			return "", err
+		}
+		sent := time.Now()
+		response, raw, err := w.query(ctx, server, q)
+		release()
+		evidenceFrom(ctx).addWhois(sent, server, q, raw, response)
+		if !rateLimited(response) {
+			return response, err
+		}
+		if attempt >= w.Retries {
+			return "", fmt.Errorf("%s: %w", server, ErrWhoisRateLimited)
+		}
+		g.delay(backoff)
+		backoff *= 2
+	}
+}
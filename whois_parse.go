@@ -0,0 +1,300 @@
+package tldbuster
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// whoisField identifies a piece of registration data.
+type whoisField int
+
+const (
+	fieldNone whoisField = iota
+	fieldRegistrant
+	fieldAdmin
+	fieldRegistrantOrg
+	fieldRegistrantCountry
+	fieldRegistrantEmail
+	fieldRegistrar
+	fieldAbuseEmail
+	fieldAbusePhone
+	fieldCreated
+	fieldUpdated
+	fieldExpires
+	fieldStatus
+	fieldNameServer
+)
+
+// whoisKeys maps normalised WHOIS keys to the field they carry. The lists
+// cover the ICANN-mandated format and the common legacy spellings.
+var whoisKeys = map[string]whoisField{
+	"registrant name":                        fieldRegistrant,
+	"registrant":                             fieldRegistrant,
+	"registrant contact":                     fieldRegistrant,
+	"admin name":                             fieldAdmin,
+	"registrant organization":                fieldRegistrantOrg,
+	"registrant organisation":                fieldRegistrantOrg,
+	"registrant org":                         fieldRegistrantOrg,
+	"registrant country":                     fieldRegistrantCountry,
+	"registrant country code":                fieldRegistrantCountry,
+	"registrant email":                       fieldRegistrantEmail,
+	"registrant e-mail":                      fieldRegistrantEmail,
+	"registrar":                              fieldRegistrar,
+	"registrar name":                         fieldRegistrar,
+	"sponsoring registrar":                   fieldRegistrar,
+	"registrar abuse contact email":          fieldAbuseEmail,
+	"abuse contact email":                    fieldAbuseEmail,
+	"abuse-mailbox":                          fieldAbuseEmail,
+	"registrar abuse contact phone":          fieldAbusePhone,
+	"abuse contact phone":                    fieldAbusePhone,
+	"creation date":                          fieldCreated,
+	"created":                                fieldCreated,
+	"created on":                             fieldCreated,
+	"created date":                           fieldCreated,
+	"registered":                             fieldCreated,
+	"registered on":                          fieldCreated,
+	"registration date":                      fieldCreated,
+	"registration time":                      fieldCreated,
+	"domain registration date":               fieldCreated,
+	"updated date":                           fieldUpdated,
+	"updated":                                fieldUpdated,
+	"updated on":                             fieldUpdated,
+	"last updated":                           fieldUpdated,
+	"last updated on":                        fieldUpdated,
+	"last modified":                          fieldUpdated,
+	"last-update":                            fieldUpdated,
+	"modified":                               fieldUpdated,
+	"changed":                                fieldUpdated,
+	"registry expiry date":                   fieldExpires,
+	"registrar registration expiration date": fieldExpires,
+	"expiration date":                        fieldExpires,
+	"expiration time":                        fieldExpires,
+	"expiry date":                            fieldExpires,
+	"expires":                                fieldExpires,
+	"expires on":                             fieldExpires,
+	"expire":                                 fieldExpires,
+	"paid-till":                              fieldExpires,
+	"renewal date":                           fieldExpires,
+	"domain expiration date":                 fieldExpires,
+	"domain status":                          fieldStatus,
+	"status":                                 fieldStatus,
+	"name server":                            fieldNameServer,
+	"name servers":                           fieldNameServer,
+	"nameserver":                             fieldNameServer,
+	"nameservers":                            fieldNameServer,
+	"nserver":                                fieldNameServer,
+}
+
+// whoisLineRe splits a "Key: value" WHOIS line.
+var whoisLineRe = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9 /()._-]*?)\s*:\s*(.*?)\s*$`)
+
+// whoisDateLayouts are the date formats seen in WHOIS responses, tried in order.
+var whoisDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05-07",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006.01.02 15:04:05",
+	"2006.01.02",
+	"2006/01/02 15:04:05 -0700",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"2006. 01. 02.",
+	"20060102",
+	"02-Jan-2006 15:04:05 MST",
+	"02-Jan-2006",
+	"2-Jan-2006",
+	"02.01.2006 15:04:05",
+	"02.01.2006",
+	"02/01/2006 15:04:05",
+	"02/01/2006",
+	"January 2 2006",
+	"2 January 2006",
+	"Mon Jan 2 15:04:05 MST 2006",
+	"Mon Jan _2 15:04:05 2006",
+}
+
+var (
+	// parenRe matches a parenthesised suffix such as "(JST)".
+	parenRe = regexp.MustCompile(`\s*\([^)]*\)\s*$`)
+	// ordinalRe matches day ordinals like "1st" or "22nd".
+	ordinalRe = regexp.MustCompile(`\b(\d{1,2})(?:st|nd|rd|th)\b`)
+)
+
+// parseWhoisDate normalises a WHOIS date to UTC.
+func parseWhoisDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(parenRe.ReplaceAllString(s, ""))
+	s = strings.TrimSuffix(s, " UTC")
+	s = strings.TrimSuffix(s, " GMT")
+	s = strings.Join(strings.Fields(s), " ")
+	// Ordinal suffixes ("1st January 2020") defeat every layout.
+	s = ordinalRe.ReplaceAllString(s, "$1")
+	for _, layout := range whoisDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseWhois is the generic parser for "Key: value" WHOIS responses. For
+// single-valued fields the first occurrence wins, which lets a registrar
+// response placed before the registry response take precedence.
+func parseWhois(response string) *WhoisRecord {
+	b := newWhoisBuilder()
+	for _, line := range strings.Split(response, "\n") {
+		if key, value, ok := splitWhoisLine(line); ok {
+			b.set(whoisKeys[key], value)
+		}
+	}
+	return b.finish()
+}
+
+// splitWhoisLine splits a "Key: value" line into its normalised key and
+// value. Comment lines and keys without a value are rejected.
+func splitWhoisLine(line string) (string, string, bool) {
+	line = strings.TrimRight(line, "\r")
+	if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "%") || strings.HasPrefix(trimmed, "#") {
+		return "", "", false
+	}
+	m := whoisLineRe.FindStringSubmatch(line)
+	if m == nil || m[2] == "" {
+		return "", "", false
+	}
+	return strings.Join(strings.Fields(strings.ToLower(m[1])), " "), m[2], true
+}
+
+// whoisBuilder accumulates fields into a WhoisRecord. It is shared by the
+// generic parser and the per-registry parsers.
+type whoisBuilder struct {
+	rec   *WhoisRecord
+	admin string
+	seen  map[string]bool
+}
+
+// newWhoisBuilder returns an empty builder.
+func newWhoisBuilder() *whoisBuilder {
+	return &whoisBuilder{rec: &WhoisRecord{Source: "whois"}, seen: make(map[string]bool)}
+}
+
+// set stores value in field. Single-valued fields keep their first value;
+// statuses and name servers are collected without duplicates.
+func (b *whoisBuilder) set(field whoisField, value string) {
+	rec := b.rec
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	switch field {
+	case fieldRegistrant:
+		setFirst(&rec.Registrant, value)
+	case fieldAdmin:
+		setFirst(&b.admin, value)
+	case fieldRegistrantOrg:
+		setFirst(&rec.RegistrantOrg, value)
+	case fieldRegistrantCountry:
+		setFirst(&rec.RegistrantCountry, value)
+	case fieldRegistrantEmail:
+		setFirst(&rec.RegistrantEmail, value)
+	case fieldRegistrar:
+		setFirst(&rec.Registrar, value)
+	case fieldAbuseEmail:
+		setFirst(&rec.AbuseEmail, value)
+	case fieldAbusePhone:
+		setFirst(&rec.AbusePhone, value)
+	case fieldCreated:
+		setFirstDate(&rec.Created, value)
+	case fieldUpdated:
+		setFirstDate(&rec.Updated, value)
+	case fieldExpires:
+		setFirstDate(&rec.Expires, value)
+	case fieldStatus:
+		// ICANN statuses carry a trailing URL: "clientHold https://icann.org/epp#clientHold".
+		status := strings.TrimSpace(parenRe.ReplaceAllString(value, ""))
+		if i := strings.Index(status, " http"); i >= 0 {
+			status = status[:i]
+		}
+		if status != "" && !b.seen["s:"+strings.ToLower(status)] {
+			b.seen["s:"+strings.ToLower(status)] = true
+			rec.Status = append(rec.Status, status)
+		}
+	case fieldNameServer:
+		// Some registries append the glue addresses.
+		ns := strings.TrimSuffix(strings.ToLower(strings.Fields(value)[0]), ".")
+		if !b.seen["n:"+ns] {
+			b.seen["n:"+ns] = true
+			rec.NameServers = append(rec.NameServers, ns)
+		}
+	}
+}
+
+// finish completes and returns the record.
+func (b *whoisBuilder) finish() *WhoisRecord {
+	if b.rec.Registrant == "" {
+		b.rec.Registrant = b.admin
+	}
+	b.rec.addDateEvents()
+	return b.rec
+}
+
+// setFirst stores value in dst unless dst is already set.
+func setFirst(dst *string, value string) {
+	if *dst == "" {
+		*dst = value
+	}
+}
+
+// setFirstDate parses value into dst unless dst is already set.
+func setFirstDate(dst **time.Time, value string) {
+	if *dst != nil {
+		return
+	}
+	if t, ok := parseWhoisDate(value); ok {
+		*dst = &t
+	}
+}
+
+// addDateEvents mirrors the normalised dates into Events, using the RDAP
+// event action names, so both sources expose the same timeline.
+func (r *WhoisRecord) addDateEvents() {
+	for _, ev := range []struct {
+		action string
+		date   *time.Time
+	}{
+		{"registration", r.Created},
+		{"last changed", r.Updated},
+		{"expiration", r.Expires},
+	} {
+		if ev.date != nil {
+			r.Events = append(r.Events, Event{Action: ev.action, Date: *ev.date})
+		}
+	}
+}
+
+// Age returns how long ago the domain was registered, or zero when the
+// creation date is unknown.
+func (r *WhoisRecord) Age(now time.Time) time.Duration {
+	if r.Created == nil {
+		return 0
+	}
+	return now.Sub(*r.Created)
+}
+
+// TransferLocked reports whether a client or server transfer lock is set.
+func (r *WhoisRecord) TransferLocked() bool {
+	for _, status := range r.Status {
+		s := strings.ToLower(strings.ReplaceAll(status, " ", ""))
+		if s == "clienttransferprohibited" || s == "servertransferprohibited" {
+			return true
+		}
+	}
+	return false
+}
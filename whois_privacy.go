@@ -0,0 +1,88 @@
+package tldbuster
+
+import "regexp"
+
+// RegistrantPrivacy classifies the registrant data of a WHOIS record.
+type RegistrantPrivacy string
+
+const (
+	// PrivacyPresent means the registrant is identified.
+	PrivacyPresent RegistrantPrivacy = "present"
+	// PrivacyRedacted means the registry or registrar masks the registrant,
+	// typically under the GDPR.
+	PrivacyRedacted RegistrantPrivacy = "redacted"
+	// PrivacyProxy means a privacy or proxy service is registered in place
+	// of the real registrant.
+	PrivacyProxy RegistrantPrivacy = "privacy-proxy"
+	// PrivacyMissing means the record carries no registrant data at all.
+	PrivacyMissing RegistrantPrivacy = "missing"
+)
+
+// privacyProviders are well-known privacy and proxy services, matched
+// against the registrant name, organisation and email.
+var privacyProviders = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"Domains By Proxy", regexp.MustCompile(`(?i)domains ?by ?proxy|registration private`)},
+	{"Withheld for Privacy", regexp.MustCompile(`(?i)withheld ?for ?privacy`)},
+	{"WhoisGuard", regexp.MustCompile(`(?i)whois ?guard`)},
+	{"PrivacyGuardian.org", regexp.MustCompile(`(?i)privacy ?guardian`)},
+	{"Contact Privacy", regexp.MustCompile(`(?i)contact ?privacy`)},
+	{"Perfect Privacy", regexp.MustCompile(`(?i)perfect ?privacy`)},
+	{"Privacy Protect", regexp.MustCompile(`(?i)privacy ?protect(?:\.org| llc)`)},
+	{"Whois Privacy Protection Service", regexp.MustCompile(`(?i)whois ?privacy ?protection`)},
+	{"Whois Privacy Corp", regexp.MustCompile(`(?i)whois ?privacy ?corp`)},
+	{"Domain Protection Services", regexp.MustCompile(`(?i)domain protection services`)},
+	{"Domain Privacy Service FBO", regexp.MustCompile(`(?i)domain privacy service fbo`)},
+	{"Super Privacy Service", regexp.MustCompile(`(?i)super privacy service`)},
+	{"Private by Design", regexp.MustCompile(`(?i)private by design`)},
+	{"Identity Protection Service", regexp.MustCompile(`(?i)identity protection service`)},
+	{"Proxy Protection", regexp.MustCompile(`(?i)proxy protection llc|proxy\.dreamhost\.com`)},
+	{"Moniker Privacy Services", regexp.MustCompile(`(?i)moniker privacy`)},
+	{"Njalla", regexp.MustCompile(`(?i)njalla|1337 services`)},
+	{"whoisproxy.com", regexp.MustCompile(`(?i)whoisproxy\.com`)},
+}
+
+// proxyRe matches privacy services missing from privacyProviders.
+var proxyRe = regexp.MustCompile(`(?i)privacy (?:service|protection)|whois (?:privacy|proxy|agent)|proxy service|private registration`)
+
+// redactedRe matches the placeholders registries and registrars put in
+// place of masked contact data, including contact form URLs given instead
+// of an email address.
+var redactedRe = regexp.MustCompile(`(?i)^https?://|redacted|not disclosed|data protected|withheld|gdpr|statutory masking|non-public|hidden upon user request|not available from registry|query the rdds service`)
+
+// Privacy classifies the registrant data of the record. For privacy and
+// proxy services the provider name is returned as well, when known.
+func (r *WhoisRecord) Privacy() (RegistrantPrivacy, string) {
+	values := []string{r.Registrant, r.RegistrantOrg, r.RegistrantEmail}
+	identified, redacted := false, false
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		for _, p := range privacyProviders {
+			if p.re.MatchString(v) {
+				return PrivacyProxy, p.name
+			}
+		}
+		if proxyRe.MatchString(v) {
+			return PrivacyProxy, ""
+		}
+		if redactedRe.MatchString(v) {
+			redacted = true
+		} else {
+			identified = true
+		}
+	}
+	switch {
+	case identified:
+		// A redacted name next to a published organisation still
+		// identifies the registrant.
+		return PrivacyPresent, ""
+	case redacted:
+		return PrivacyRedacted, ""
+	default:
+		return PrivacyMissing, ""
+	}
+}
@@ -0,0 +1,339 @@
+package tldbuster
+
+import (
+	"regexp"
+	"strings"
+)
+
+// WhoisParser extracts a record from a raw WHOIS response.
+type WhoisParser func(response string) *WhoisRecord
+
+// whoisParsers holds the dedicated parsers of registries whose WHOIS
+// output does not follow the ICANN "Key: value" format. Every other TLD
+// uses parseWhois.
+var whoisParsers = map[string]WhoisParser{
+	"de": parseDENIC,
+	"jp": parseJPRS,
+	"uk": parseNominet,
+	"nl": parseSIDN,
+	"br": parseRegistroBR,
+	"fr": parseAFNIC,
+	"re": parseAFNIC,
+	"pm": parseAFNIC,
+	"tf": parseAFNIC,
+	"wf": parseAFNIC,
+	"yt": parseAFNIC,
+}
+
+// whoisParser returns the parser for tld: a client override, a dedicated
+// registry parser or the generic fallback.
+func (w *PortWhois) whoisParser(tld string) WhoisParser {
+	if p, ok := w.Parsers[tld]; ok {
+		return p
+	}
+	if p, ok := whoisParsers[tld]; ok {
+		return p
+	}
+	return parseWhois
+}
+
+// parseDENIC parses .de responses: a flat header followed by contact
+// sections such as "[Holder]".
+func parseDENIC(response string) *WhoisRecord {
+	b := newWhoisBuilder()
+	section := ""
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.ToLower(strings.Trim(trimmed, "[]"))
+			continue
+		}
+		key, value, ok := splitWhoisLine(line)
+		if !ok {
+			continue
+		}
+		switch section {
+		case "":
+			b.set(map[string]whoisField{
+				"nserver": fieldNameServer,
+				"status":  fieldStatus,
+				"changed": fieldUpdated,
+			}[key], value)
+		case "holder":
+			b.set(map[string]whoisField{
+				"name":         fieldRegistrant,
+				"organisation": fieldRegistrantOrg,
+				"countrycode":  fieldRegistrantCountry,
+				"email":        fieldRegistrantEmail,
+			}[key], value)
+		}
+	}
+	return b.finish()
+}
+
+// jprsLineRe matches "a. [Key]   value" lines of JPRS responses.
+var jprsLineRe = regexp.MustCompile(`^\s*(?:[a-z]\.\s*)?\[([^\]]+)\]\s*(.*?)\s*$`)
+
+// jprsKeys maps JPRS keys, in English (/e) and Japanese, to fields.
+var jprsKeys = map[string]whoisField{
+	"registrant":      fieldRegistrant,
+	"登録者名":            fieldRegistrant,
+	"organization":    fieldRegistrantOrg,
+	"組織名":             fieldRegistrantOrg,
+	"name server":     fieldNameServer,
+	"ネームサーバ":          fieldNameServer,
+	"created on":      fieldCreated,
+	"registered date": fieldCreated,
+	"登録年月日":           fieldCreated,
+	"last updated":    fieldUpdated,
+	"last update":     fieldUpdated,
+	"最終更新":            fieldUpdated,
+	"expires on":      fieldExpires,
+	"有効期限":            fieldExpires,
+	"status":          fieldStatus,
+	"ステータス":           fieldStatus,
+}
+
+// parseJPRS parses .jp responses with their bracketed keys. The [State]
+// of organisational domains embeds the expiry date: "Connected (2025/03/31)".
+func parseJPRS(response string) *WhoisRecord {
+	b := newWhoisBuilder()
+	for _, line := range strings.Split(response, "\n") {
+		m := jprsLineRe.FindStringSubmatch(line)
+		if m == nil || m[2] == "" {
+			continue
+		}
+		// JPRS gives local times with a "(JST)" suffix.
+		key, value := strings.ToLower(m[1]), strings.Replace(m[2], "(JST)", "+0900", 1)
+		if key == "state" || key == "状態" {
+			status, date, _ := strings.Cut(value, "(")
+			b.set(fieldStatus, status)
+			b.set(fieldExpires, strings.TrimSuffix(date, ")"))
+			continue
+		}
+		if field := jprsKeys[key]; field == fieldRegistrantOrg {
+			// Organisational domains name the registrant organisation only.
+			b.set(fieldRegistrant, value)
+			b.set(field, value)
+		} else {
+			b.set(field, value)
+		}
+	}
+	return b.finish()
+}
+
+// parseSections groups an indented response into sections: a header line
+// ending in ":" followed by indented value lines. Headers are lowercased.
+func parseSections(response string) map[string][]string {
+	sections := make(map[string][]string)
+	current := ""
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			current = ""
+			continue
+		}
+		if strings.HasSuffix(trimmed, ":") && !strings.Contains(strings.TrimSuffix(trimmed, ":"), ":") {
+			current = strings.ToLower(strings.TrimSuffix(trimmed, ":"))
+			continue
+		}
+		if current != "" && line != trimmed {
+			sections[current] = append(sections[current], trimmed)
+		}
+	}
+	return sections
+}
+
+// nominetTagRe matches the registrar tag suffix of Nominet responses.
+var nominetTagRe = regexp.MustCompile(`\s*\[Tag = [^\]]*\]\s*$`)
+
+// parseNominet parses .uk responses, which group values under headers.
+func parseNominet(response string) *WhoisRecord {
+	b := newWhoisBuilder()
+	sections := parseSections(response)
+	for _, v := range sections["registrant"] {
+		b.set(fieldRegistrant, v)
+	}
+	if registrar := sections["registrar"]; len(registrar) > 0 {
+		b.set(fieldRegistrar, nominetTagRe.ReplaceAllString(registrar[0], ""))
+	}
+	for _, line := range sections["relevant dates"] {
+		key, value, ok := splitWhoisLine(line)
+		if !ok {
+			continue
+		}
+		b.set(map[string]whoisField{
+			"registered on": fieldCreated,
+			"expiry date":   fieldExpires,
+			"last updated":  fieldUpdated,
+		}[key], value)
+	}
+	for _, v := range sections["registration status"] {
+		b.set(fieldStatus, strings.TrimSuffix(v, "."))
+	}
+	for _, v := range sections["name servers"] {
+		b.set(fieldNameServer, v)
+	}
+	return b.finish()
+}
+
+// parseSIDN parses .nl responses: inline keys plus indented sections.
+func parseSIDN(response string) *WhoisRecord {
+	b := newWhoisBuilder()
+	for _, line := range strings.Split(response, "\n") {
+		key, value, ok := splitWhoisLine(line)
+		if !ok {
+			continue
+		}
+		b.set(map[string]whoisField{
+			"status":        fieldStatus,
+			"creation date": fieldCreated,
+			"updated date":  fieldUpdated,
+		}[key], value)
+	}
+	sections := parseSections(response)
+	if registrar := sections["registrar"]; len(registrar) > 0 {
+		b.set(fieldRegistrar, registrar[0])
+	}
+	if registrant := sections["registrant"]; len(registrant) > 0 {
+		b.set(fieldRegistrant, registrant[0])
+	}
+	for _, v := range sections["abuse contact"] {
+		if strings.Contains(v, "@") {
+			b.set(fieldAbuseEmail, v)
+		} else {
+			b.set(fieldAbusePhone, v)
+		}
+	}
+	for _, v := range sections["domain nameservers"] {
+		b.set(fieldNameServer, v)
+	}
+	return b.finish()
+}
+
+// whoisBlock is one blank-line separated object of an RPSL-style response.
+type whoisBlock []struct{ key, value string }
+
+// get returns the first value of key in the block.
+func (blk whoisBlock) get(key string) string {
+	for _, kv := range blk {
+		if kv.key == key {
+			return kv.value
+		}
+	}
+	return ""
+}
+
+// parseBlocks splits an RPSL-style response into its objects.
+func parseBlocks(response string) []whoisBlock {
+	var blocks []whoisBlock
+	var current whoisBlock
+	for _, line := range strings.Split(response, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		if key, value, ok := splitWhoisLine(line); ok {
+			current = append(current, struct{ key, value string }{key, value})
+		}
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+// parseAFNIC parses .fr (and other AFNIC) responses. The registrant is a
+// separate contact object referenced by the holder-c handle.
+func parseAFNIC(response string) *WhoisRecord {
+	b := newWhoisBuilder()
+	blocks := parseBlocks(response)
+	holder := ""
+	for _, blk := range blocks {
+		if blk.get("domain") == "" {
+			continue
+		}
+		holder = blk.get("holder-c")
+		hasEPP := blk.get("eppstatus") != ""
+		for _, kv := range blk {
+			switch kv.key {
+			case "eppstatus":
+				b.set(fieldStatus, kv.value)
+			case "status":
+				if !hasEPP {
+					b.set(fieldStatus, kv.value)
+				}
+			case "registrar":
+				b.set(fieldRegistrar, kv.value)
+			case "expiry date":
+				b.set(fieldExpires, kv.value)
+			case "created":
+				b.set(fieldCreated, kv.value)
+			case "last-update":
+				b.set(fieldUpdated, kv.value)
+			}
+		}
+		break
+	}
+	for _, blk := range blocks {
+		for _, kv := range blk {
+			if kv.key == "nserver" {
+				b.set(fieldNameServer, kv.value)
+			}
+		}
+		if holder != "" && strings.EqualFold(blk.get("nic-hdl"), holder) {
+			b.set(fieldRegistrant, blk.get("contact"))
+			if strings.EqualFold(blk.get("type"), "ORGANIZATION") {
+				b.set(fieldRegistrantOrg, blk.get("contact"))
+			}
+			b.set(fieldRegistrantCountry, blk.get("country"))
+			b.set(fieldRegistrantEmail, blk.get("e-mail"))
+		}
+	}
+	return b.finish()
+}
+
+// parseRegistroBR parses .br responses. Dates are compact (YYYYMMDD) and
+// may carry a ticket number after "#".
+func parseRegistroBR(response string) *WhoisRecord {
+	b := newWhoisBuilder()
+	blocks := parseBlocks(response)
+	ownerC := ""
+	for _, blk := range blocks {
+		if blk.get("domain") == "" {
+			continue
+		}
+		ownerC = blk.get("owner-c")
+		for _, kv := range blk {
+			value, _, _ := strings.Cut(kv.value, "#")
+			switch kv.key {
+			case "owner":
+				b.set(fieldRegistrant, value)
+				b.set(fieldRegistrantOrg, value)
+			case "country":
+				b.set(fieldRegistrantCountry, value)
+			case "nserver":
+				b.set(fieldNameServer, value)
+			case "created":
+				b.set(fieldCreated, value)
+			case "changed":
+				b.set(fieldUpdated, value)
+			case "expires":
+				b.set(fieldExpires, value)
+			case "status":
+				b.set(fieldStatus, value)
+			}
+		}
+		break
+	}
+	for _, blk := range blocks {
+		if ownerC != "" && strings.EqualFold(blk.get("nic-hdl-br"), ownerC) {
+			b.set(fieldRegistrantEmail, blk.get("e-mail"))
+		}
+	}
+	return b.finish()
+}
@@ -0,0 +1,118 @@
+package tldbuster
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWhoisParsers(t *testing.T) {
+	date := func(s string) *time.Time {
+		d, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d = d.UTC()
+		return &d
+	}
+
+	tests := []struct {
+		tld  string
+		want WhoisRecord
+	}{
+		{"com", WhoisRecord{
+			Registrar:   "RESERVED-Internet Assigned Numbers Authority",
+			Created:     date("1995-08-14T04:00:00Z"),
+			Updated:     date("2024-08-14T07:01:34Z"),
+			Expires:     date("2025-08-13T04:00:00Z"),
+			Status:      []string{"clientDeleteProhibited", "clientTransferProhibited", "clientUpdateProhibited"},
+			NameServers: []string{"a.iana-servers.net", "b.iana-servers.net"},
+		}},
+		{"de", WhoisRecord{
+			Registrant:        "Beispiel GmbH",
+			RegistrantOrg:     "Beispiel GmbH",
+			RegistrantCountry: "DE",
+			RegistrantEmail:   "hostmaster@beispiel.de",
+			Updated:           date("2023-06-14T09:44:32+02:00"),
+			Status:            []string{"connect"},
+			NameServers:       []string{"ns1.example.net", "ns2.example.net"},
+		}},
+		{"jp", WhoisRecord{
+			Registrant:    "Example Corporation",
+			RegistrantOrg: "Example Corporation",
+			Created:       date("2001-03-28T00:00:00Z"),
+			Updated:       date("2024-04-01T01:05:10+09:00"),
+			Expires:       date("2025-03-31T00:00:00Z"),
+			Status:        []string{"Connected"},
+			NameServers:   []string{"ns1.example.co.jp", "ns2.example.co.jp"},
+		}},
+		{"uk", WhoisRecord{
+			Registrar:   "Example Registrar Ltd",
+			Created:     date("1996-11-26T00:00:00Z"),
+			Updated:     date("2023-11-10T00:00:00Z"),
+			Expires:     date("2025-11-26T00:00:00Z"),
+			Status:      []string{"Registered until expiry date"},
+			NameServers: []string{"ns1.example.net", "ns2.example.net"},
+		}},
+		{"fr", WhoisRecord{
+			Registrant:        "Exemple SA",
+			RegistrantOrg:     "Exemple SA",
+			RegistrantCountry: "FR",
+			RegistrantEmail:   "hostmaster@exemple.fr",
+			Registrar:         "EXEMPLE REGISTRAR",
+			Created:           date("2000-02-24T00:00:00Z"),
+			Updated:           date("2024-02-01T08:00:00Z"),
+			Expires:           date("2025-02-24T10:14:06Z"),
+			Status:            []string{"serverTransferProhibited", "active"},
+			NameServers:       []string{"ns1.example.net", "ns2.example.net"},
+		}},
+		{"nl", WhoisRecord{
+			Registrar:   "Voorbeeld Registrar B.V.",
+			AbuseEmail:  "abuse@voorbeeld-registrar.nl",
+			AbusePhone:  "+31.201234567",
+			Created:     date("1999-05-27T00:00:00Z"),
+			Updated:     date("2023-01-01T00:00:00Z"),
+			Status:      []string{"active"},
+			NameServers: []string{"ns1.example.nl", "ns2.example.nl"},
+		}},
+		{"br", WhoisRecord{
+			Registrant:        "Exemplo Comercio Ltda",
+			RegistrantOrg:     "Exemplo Comercio Ltda",
+			RegistrantCountry: "BR",
+			RegistrantEmail:   "hostmaster@exemplo.com.br",
+			Created:           date("1999-03-15T00:00:00Z"),
+			Updated:           date("2023-08-10T00:00:00Z"),
+			Expires:           date("2025-03-15T00:00:00Z"),
+			Status:            []string{"published"},
+			NameServers:       []string{"ns1.exemplo.com.br", "ns2.exemplo.com.br"},
+		}},
+	}
+
+	w := NewWhoisClient("")
+	for _, tt := range tests {
+		t.Run(tt.tld, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "whois", tt.tld+".txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := w.whoisParser(tt.tld)(string(data))
+			// Events only mirror the dates checked below.
+			got.Events = nil
+			tt.want.Source = "whois"
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("parse %s.txt:\n got %+v\nwant %+v", tt.tld, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhoisParserOverride(t *testing.T) {
+	w := NewWhoisClient("")
+	w.Parsers = map[string]WhoisParser{"de": parseWhois}
+	rec := w.whoisParser("de")("Registrar: Example\n")
+	if rec.Registrar != "Example" {
+		t.Errorf("override parser not used: %+v", rec)
+	}
+}
@@ -0,0 +1,160 @@
+package tldbuster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ianaWhoisServer answers per-TLD referrals to the registry WHOIS servers.
+const ianaWhoisServer = "whois.iana.org"
+
+// whoisServerTTL is how long a discovered server stays valid on disk.
+const whoisServerTTL = 30 * 24 * time.Hour
+
+// ErrNoWhoisServer is returned when no WHOIS server is known for a TLD.
+var ErrNoWhoisServer = errors.New("no WHOIS server for TLD")
+
+var (
+	ianaWhoisRe = regexp.MustCompile(`(?im)^\s*whois:\s*(\S+)`)
+	ianaReferRe = regexp.MustCompile(`(?im)^\s*refer:\s*(\S+)`)
+)
+
+// whoisServerEntry is a cached referral. An empty Server records that IANA
+// knows no WHOIS server for the TLD.
+type whoisServerEntry struct {
+	Server  string    `json:"server"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// whoisReferral is the outcome of asking IANA for the server of a TLD.
+type whoisReferral struct {
+	server string
+	err    error
+}
+
+// whoisServerCache keeps discovered WHOIS servers in memory and, when a
+// path is set, in a JSON file shared between runs.
+type whoisServerCache struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]whoisServerEntry
+}
+
+// lookup returns a fresh cached entry for tld.
+func (c *whoisServerCache) lookup(tld string) (whoisServerEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	entry, ok := c.entries[tld]
+	if !ok || time.Since(entry.Fetched) > whoisServerTTL {
+		return whoisServerEntry{}, false
+	}
+	return entry, true
+}
+
+// store records the server of tld and persists the cache.
+func (c *whoisServerCache) store(tld, server string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	c.entries[tld] = whoisServerEntry{Server: server, Fetched: time.Now().UTC()}
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	// Write through a temporary file so a crash never leaves a torn cache.
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// load reads the cache file on first use. A missing or corrupt file simply
+// starts an empty cache. c.mu must be held.
+func (c *whoisServerCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]whoisServerEntry)
+	if c.path == "" {
+		return
+	}
+	if data, err := os.ReadFile(c.path); err == nil {
+		json.Unmarshal(data, &c.entries)
+	}
+}
+
+// DefaultWhoisCachePath returns the default location of the WHOIS server
+// cache inside the user's cache directory.
+func DefaultWhoisCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "tldbuster", "whois-servers.json")
+}
+
+// whoisServer returns the WHOIS server of tld: an override if one is set,
+// otherwise the referral given by IANA, cached per TLD. Failed queries to
+// IANA are not cached, so the next lookup under the TLD asks again.
+func (w *PortWhois) whoisServer(ctx context.Context, tld string) (string, error) {
+	if server, ok := w.Overrides[tld]; ok {
+		return server, nil
+	}
+	if entry, ok := w.cache.lookup(tld); ok {
+		if entry.Server == "" {
+			return "", ErrNoWhoisServer
+		}
+		return entry.Server, nil
+	}
+
+	ref := w.referrals.getUnlessFailed(tld, func() (whoisReferral, bool) {
+		ctx := withEvidence(ctx, nil)
+		server, err := w.askIANA(ctx, tld)
+		if err != nil {
+			return whoisReferral{err: err}, false
+		}
+		// A cache that cannot be written only costs a repeated query next run.
+		w.cache.store(tld, server)
+		return whoisReferral{server: server}, true
+	})
+	if ref.server == "" && ref.err == nil {
+		return "", ErrNoWhoisServer
+	}
+	return ref.server, ref.err
+}
+
+// askIANA queries the IANA WHOIS server for the referral of tld.
+func (w *PortWhois) askIANA(ctx context.Context, tld string) (string, error) {
+	iana := w.IANAServer
+	if iana == "" {
+		iana = ianaWhoisServer
+	}
+	response, err := w.limitedQuery(ctx, iana, tld)
+	if response == "" {
+		return "", err
+	}
+	if m := ianaWhoisRe.FindStringSubmatch(response); m != nil {
+		return m[1], nil
+	}
+	if m := ianaReferRe.FindStringSubmatch(response); m != nil {
+		return m[1], nil
+	}
+	return "", nil
+}
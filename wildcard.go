@@ -0,0 +1,87 @@
+package tldbuster
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// WildcardPolicy decides what happens to candidates whose answers match a
+// wildcard record of their suffix.
+type WildcardPolicy int
+
+const (
+	// WildcardDrop discards candidates that match the wildcard answers.
+	WildcardDrop WildcardPolicy = iota
+	// WildcardFlag keeps such candidates and marks them on the Result.
+	WildcardFlag
+	// WildcardOff disables wildcard detection.
+	WildcardOff
+)
+
+// wildcardProbes is the number of random labels queried per suffix.
+const wildcardProbes = 3
+
+// wildcardLabelLen is the length of the random probe labels. Long random
+// labels are certain not to be registered.
+const wildcardLabelLen = 24
+
+// wildcardAnswers probes random labels under suffix once and returns the
+// set of addresses they resolved to. An empty set means no wildcard.
+func (s *Scanner) wildcardAnswers(ctx context.Context, suffix string) map[string]struct{} {
+	return s.wildcards.get(suffix, func() map[string]struct{} {
+		// The probes serve every candidate of the suffix, not one result.
+		ctx := withEvidence(ctx, nil)
+		answers := make(map[string]struct{})
+		for i := 0; i < wildcardProbes; i++ {
+			probe := randomLabel(wildcardLabelLen) + "." + suffix
+			if status, ips := s.checkDomain(ctx, probe); status == StatusResolved {
+				for _, ip := range ips {
+					answers[ip] = struct{}{}
+				}
+			}
+		}
+		if len(answers) > 0 {
+			s.debugf("Wildcard DNS detected under .%s: %s", suffix, strings.Join(sortedKeys(answers), ", "))
+		}
+		return answers
+	})
+}
+
+// matchesWildcard reports whether every address of a candidate is part of
+// the wildcard answers of its suffix.
+func (s *Scanner) matchesWildcard(ctx context.Context, suffix string, ips []string) bool {
+	wildcard := s.wildcardAnswers(ctx, suffix)
+	if len(wildcard) == 0 || len(ips) == 0 {
+		return false
+	}
+	for _, ip := range ips {
+		if _, ok := wildcard[ip]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// randomLabel returns a random lowercase LDH label of length n.
+func randomLabel(n int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[rand.IntN(len(alphabet))]
+	}
+	// Start with a letter so the label can never look numeric.
+	b[0] = alphabet[rand.IntN(26)]
+	return string(b)
+}
+
+// sortedKeys returns the keys of a set in sorted order.
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}